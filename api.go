@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"spire/entry"
+	"spire/storage"
+	"spire/token"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The embedding is left out of API responses; it's large and only useful to
+// Spire itself. Private entries are sent with only their ID and time, since
+// there's no password to enter over the API.
+type apiEntry struct {
+	ID          int64           `json:"id"`
+	Time        time.Time       `json:"time"`
+	Content     string          `json:"content"`
+	Tags        []string        `json:"tags"`
+	Attachments []apiAttachment `json:"attachments"`
+	Private     bool            `json:"private"`
+	NotebookID  int64           `json:"notebook_id"`
+}
+
+type apiAttachment struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+func toAPIEntry(e entry.Entry) apiEntry {
+	if e.Private {
+		return apiEntry{ID: e.ID, Time: e.Time, Tags: []string{}, Attachments: []apiAttachment{}, Private: true, NotebookID: e.NotebookID}
+	}
+
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	attachments := make([]apiAttachment, len(e.Attachments))
+	for i, a := range e.Attachments {
+		attachments[i] = apiAttachment{
+			ID:          a.ID,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			URL:         fmt.Sprintf("/api/entries/%d/attachments/%d", e.ID, a.ID),
+		}
+	}
+
+	return apiEntry{ID: e.ID, Time: e.Time, Content: e.Content, Tags: tags, Attachments: attachments, NotebookID: e.NotebookID}
+}
+
+func toAPIEntries(entries []entry.Entry) []apiEntry {
+	result := make([]apiEntry, len(entries))
+	for i, e := range entries {
+		result[i] = toAPIEntry(e)
+	}
+
+	return result
+}
+
+// requireToken only lets a request through if it carries a valid, unexpired
+// bearer token with the given scope.
+func (server *Server) requireToken(scope token.Scope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		plaintext, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || plaintext == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="spire"`)
+			writeJSONError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		t, err := server.Storage.GetTokenByHash(token.Hash(plaintext))
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			writeJSONError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if err != nil {
+			log.Println(err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		now := time.Now()
+
+		if t.Expired(now) {
+			writeJSONError(w, http.StatusUnauthorized, "token has expired")
+			return
+		}
+
+		if !t.HasScope(scope) {
+			writeJSONError(w, http.StatusForbidden, "token is missing the "+string(scope)+" scope")
+			return
+		}
+
+		err = server.Storage.TouchToken(t.ID, now)
+		if err != nil {
+			// Not worth failing the request over.
+			log.Printf("error updating token last used time: %v\n", err)
+		}
+
+		next(w, r)
+	}
+}
+
+func (server *Server) apiEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := server.Storage.GetEntries()
+	if err != nil {
+		log.Println(err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIEntries(entries))
+}
+
+func (server *Server) apiNewEntryHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Content string   `json:"content"`
+		Tags    []string `json:"tags"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+
+	if strings.TrimSpace(request.Content) == "" {
+		writeJSONError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	newEntry, err := server.createEntry(request.Content, request.Tags)
+	if err != nil {
+		log.Println(err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAPIEntry(newEntry))
+}
+
+// apiSearchHandler searches every notebook, or only the one given by the
+// notebook parameter.
+func (server *Server) apiSearchHandler(w http.ResponseWriter, r *http.Request) {
+	var notebookID int64
+	if notebook := r.URL.Query().Get("notebook"); notebook != "" {
+		id, err := strconv.ParseInt(notebook, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid notebook id")
+			return
+		}
+
+		notebookID = id
+	}
+
+	// There's no unlocking over the API, so private entries never match.
+	entries, err := server.search(r.URL.Query().Get("q"), false)
+	if err != nil {
+		log.Println(err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if notebookID != 0 {
+		entries = inNotebook(entries, notebookID)
+	}
+
+	writeJSON(w, http.StatusOK, toAPIEntries(entries))
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"spire/blobs"
+	"spire/entry"
+	"spire/storage"
+	"spire/thumbnail"
+	"strconv"
+	"time"
+)
+
+const (
+	// Where uploaded files are kept, next to main.db.
+	blobDir = "attachments"
+	// Per file.
+	maxAttachmentSize = 10 << 20
+	maxAttachments    = 10
+	// Room for the rest of the form alongside the files.
+	maxEntryUploadSize = maxAttachments*maxAttachmentSize + 1<<20
+	// Width and height thumbnails fit in.
+	thumbnailSize = 480
+	// How long a stored file can go without an attachment pointing at it
+	// before it's cleaned up. Files are stored just before their entry is
+	// saved, so this only needs to outlast a save.
+	orphanedBlobAge = time.Hour
+)
+
+// The kinds of files entries can have, by the type sniffed from their
+// contents. Browsers' claims about uploads aren't trusted.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+	"audio/mpeg":      true,
+	"audio/wave":      true,
+	"video/mp4":       true,
+}
+
+// Types browsers can show without running anything from the file. Anything
+// else is always downloaded.
+var inlineAttachmentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// An upload is a file that passed the checks and is ready to store.
+type upload struct {
+	header      *multipart.FileHeader
+	contentType string
+}
+
+// checkUploads makes sure files are few enough, small enough and of an
+// allowed type. Its errors are meant for the user.
+func checkUploads(files []*multipart.FileHeader) ([]upload, error) {
+	if len(files) > maxAttachments {
+		return nil, fmt.Errorf("an entry can have at most %d attachments", maxAttachments)
+	}
+
+	uploads := make([]upload, 0, len(files))
+	for _, header := range files {
+		if header.Size > maxAttachmentSize {
+			return nil, fmt.Errorf("%s is larger than %d MB", header.Filename, maxAttachmentSize>>20)
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		head := make([]byte, 512)
+		n, err := io.ReadFull(file, head)
+		file.Close()
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+		if err != nil || !attachmentTypes[contentType] {
+			return nil, fmt.Errorf("%s isn't a kind of file entries can have", header.Filename)
+		}
+
+		uploads = append(uploads, upload{header: header, contentType: contentType})
+	}
+
+	return uploads, nil
+}
+
+// storeUploads puts each file, and a thumbnail for each image, in the blob
+// store.
+func (server *Server) storeUploads(uploads []upload) ([]entry.Attachment, error) {
+	attachments := make([]entry.Attachment, 0, len(uploads))
+
+	for _, u := range uploads {
+		file, err := u.header.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		hash, size, err := server.Blobs.Put(file, maxAttachmentSize)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		a := entry.Attachment{
+			Name:        u.header.Filename,
+			ContentType: u.contentType,
+			Size:        size,
+			Hash:        hash,
+		}
+
+		if a.IsImage() {
+			a.ThumbnailHash, err = server.storeThumbnail(hash)
+			if err != nil {
+				// The file's still worth keeping without a preview.
+				log.Printf("error making a thumbnail for %s: %v\n", a.Name, err)
+			}
+		}
+
+		attachments = append(attachments, a)
+	}
+
+	return attachments, nil
+}
+
+func (server *Server) storeThumbnail(hash string) (string, error) {
+	file, err := server.Blobs.Open(hash)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := thumbnail.Make(file, thumbnailSize)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnailHash, _, err := server.Blobs.Put(bytes.NewReader(data), maxAttachmentSize)
+	return thumbnailHash, err
+}
+
+func (server *Server) attachmentHandler(w http.ResponseWriter, r *http.Request) {
+	server.serveAttachment(w, r, false)
+}
+
+func (server *Server) attachmentThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	server.serveAttachment(w, r, true)
+}
+
+// serveAttachment sends one of an entry's files, or its thumbnail. The file
+// has to belong to the entry in the path, and the entry has to be out of the
+// trash, so knowing an attachment's ID isn't enough to read it. A private
+// entry's files need an unlocked session, so they can't be fetched over the
+// API at all.
+func (server *Server) serveAttachment(w http.ResponseWriter, r *http.Request, preview bool) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if server.hidden(r, e) {
+		http.Error(w, "reveal the entry first", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("attachment"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid attachment id", http.StatusBadRequest)
+		return
+	}
+
+	a, err := server.Storage.GetAttachment(e.ID, id)
+	if errors.Is(err, storage.ErrAttachmentNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hash, contentType := a.Hash, a.ContentType
+	if preview {
+		if a.ThumbnailHash == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		hash, contentType = a.ThumbnailHash, "image/jpeg"
+	}
+
+	file, err := server.Blobs.Open(hash)
+	if errors.Is(err, blobs.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	disposition := "attachment"
+	if inlineAttachmentTypes[contentType] {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	// Files never change under the same hash.
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+
+	http.ServeContent(w, r, "", time.Time{}, file)
+}
+
+// fileSize formats a size in bytes the way file managers do.
+func fileSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%d KB", size>>10)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
+
+// collectBlobs deletes stored files that no attachment refers to anymore,
+// such as those of purged entries.
+func (server *Server) collectBlobs() error {
+	hashes, err := server.Storage.GetAttachmentHashes()
+	if err != nil {
+		return err
+	}
+
+	removed, err := server.Blobs.Collect(hashes, orphanedBlobAge)
+	if removed > 0 {
+		log.Printf("attachments: removed %d unused files\n", removed)
+	}
+
+	return err
+}
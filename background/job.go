@@ -0,0 +1,68 @@
+package background
+
+import (
+	"log"
+	"time"
+)
+
+// Job runs a function in the background, either when triggered or on an
+// interval. Triggers that arrive while the job is running are coalesced into
+// a single extra run.
+type Job struct {
+	name    string
+	run     func() error
+	trigger chan struct{}
+}
+
+func NewJob(name string, run func() error) *Job {
+	return &Job{
+		name:    name,
+		run:     run,
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Trigger asks for a run soon. It never blocks.
+func (j *Job) Trigger() {
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the job in a goroutine until the process exits. An interval of
+// zero means it only runs when triggered.
+func (j *Job) Start(interval time.Duration) {
+	go func() {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-j.trigger:
+			case <-tick:
+			}
+
+			j.RunNow()
+		}
+	}()
+}
+
+// RunNow runs the job on the calling goroutine and logs any error.
+func (j *Job) RunNow() {
+	started := time.Now()
+
+	err := j.run()
+	if err != nil {
+		log.Printf("%s: %v\n", j.name, err)
+		return
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		log.Printf("%s: finished in %s\n", j.name, elapsed.Round(time.Millisecond))
+	}
+}
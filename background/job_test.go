@@ -0,0 +1,60 @@
+package background
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTriggerCoalesces(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	job := NewJob("test", func() error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+	job.Start(0)
+
+	// The first trigger starts a run, and the rest pile up while it's blocked.
+	job.Trigger()
+	waitFor(t, func() bool { return runs.Load() == 1 })
+	for i := 0; i < 5; i++ {
+		job.Trigger()
+	}
+
+	release <- struct{}{}
+	waitFor(t, func() bool { return runs.Load() == 2 })
+	release <- struct{}{}
+
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != 2 {
+		t.Errorf("expected queued triggers to coalesce into one run, got %d runs", runs.Load())
+	}
+}
+
+func TestInterval(t *testing.T) {
+	var runs atomic.Int32
+
+	job := NewJob("test", func() error {
+		runs.Add(1)
+		return nil
+	})
+	job.Start(5 * time.Millisecond)
+
+	waitFor(t, func() bool { return runs.Load() >= 2 })
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
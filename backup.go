@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"spire/backup"
+	"spire/storage"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultBackupInterval = 24 * time.Hour
+	defaultBackupKeep     = 7
+)
+
+// backupSchedule is how the server takes backups on its own. An empty Dir
+// means it doesn't.
+type backupSchedule struct {
+	Dir      string
+	Interval time.Duration
+	Keep     int
+	Compress bool
+}
+
+func backupScheduleFromEnv() (backupSchedule, error) {
+	schedule := backupSchedule{
+		Dir:      os.Getenv("SPIRE_BACKUP_DIR"),
+		Interval: defaultBackupInterval,
+		Keep:     defaultBackupKeep,
+		Compress: true,
+	}
+
+	if value := os.Getenv("SPIRE_BACKUP_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return schedule, fmt.Errorf("SPIRE_BACKUP_INTERVAL must be a positive duration like 24h, got %q", value)
+		}
+
+		schedule.Interval = interval
+	}
+
+	if value := os.Getenv("SPIRE_BACKUP_KEEP"); value != "" {
+		keep, err := strconv.Atoi(value)
+		if err != nil || keep < 0 {
+			return schedule, fmt.Errorf("SPIRE_BACKUP_KEEP must be a whole number, got %q", value)
+		}
+
+		schedule.Keep = keep
+	}
+
+	if value := os.Getenv("SPIRE_BACKUP_GZIP"); value != "" {
+		compress, err := strconv.ParseBool(value)
+		if err != nil {
+			return schedule, fmt.Errorf("SPIRE_BACKUP_GZIP must be true or false, got %q", value)
+		}
+
+		schedule.Compress = compress
+	}
+
+	return schedule, nil
+}
+
+// takeBackup snapshots the database into dir and then drops the oldest
+// backups beyond keep.
+func takeBackup(store *storage.SQLiteStorage, dir string, compress bool, keep int) (string, []string, error) {
+	path, err := backup.Create(store, dir, compress, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	removed, err := backup.Prune(dir, keep)
+	return path, removed, err
+}
+
+// scheduledBackup is the backup job's run function.
+func scheduledBackup(store *storage.SQLiteStorage, schedule backupSchedule) func() error {
+	return func() error {
+		path, removed, err := takeBackup(store, schedule.Dir, schedule.Compress, schedule.Keep)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("backup: wrote %s, removed %d old backups\n", path, len(removed))
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+// Package backup keeps snapshots of the database as files in a folder, and
+// puts them back.
+package backup
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"spire/storage"
+	"strings"
+	"time"
+)
+
+const (
+	prefix     = "spire-"
+	extension  = ".db"
+	compressed = ".gz"
+	// Names sort in the order the backups were taken.
+	timeLayout = "20060102-150405"
+)
+
+// Create snapshots the database into dir, gzipped if compress is set, and
+// returns the new file's path.
+func Create(store *storage.SQLiteStorage, dir string, compress bool, now time.Time) (string, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, prefix+now.UTC().Format(timeLayout)+extension)
+
+	// Snapshot to a name Prune and Restore don't recognize, so a backup that
+	// fails part way never looks like a finished one.
+	partial := path + ".partial"
+	os.Remove(partial)
+
+	err = store.Backup(partial)
+	if err != nil {
+		os.Remove(partial)
+		return "", err
+	}
+
+	if compress {
+		err = compressFile(partial, path+compressed)
+		os.Remove(partial)
+		if err != nil {
+			return "", err
+		}
+
+		return path + compressed, nil
+	}
+
+	return path, os.Rename(partial, path)
+}
+
+func compressFile(source string, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(out)
+
+	_, err = io.Copy(writer, in)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = out.Close()
+	} else {
+		out.Close()
+	}
+
+	if err != nil {
+		os.Remove(destination)
+	}
+
+	return err
+}
+
+// List returns the backups in dir, oldest first.
+func List(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		if strings.HasSuffix(name, extension) || strings.HasSuffix(name, extension+compressed) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Prune deletes all but the newest keep backups in dir and returns the paths
+// it deleted. A keep of zero keeps everything.
+func Prune(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	backups, err := List(dir)
+	if err != nil || len(backups) <= keep {
+		return nil, err
+	}
+
+	var removed []string
+	for _, path := range backups[:len(backups)-keep] {
+		err := os.Remove(path)
+		if err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
+
+// Restore replaces the database at destination with a backup, compressed or
+// not. The backup is copied next to the database and checked before anything
+// is replaced, and the database it replaces is kept with a ".before-restore-"
+// suffix and the time, so restoring again doesn't lose it. It returns the
+// path the old database was kept at, or "" if there wasn't one. Nothing
+// should have the database open while this runs.
+func Restore(source string, destination string, now time.Time) (string, error) {
+	staged := destination + ".restore"
+	os.Remove(staged)
+
+	err := stage(source, staged)
+	if err != nil {
+		os.Remove(staged)
+		return "", err
+	}
+
+	err = storage.CheckDatabase(staged)
+	if err != nil {
+		os.Remove(staged)
+		return "", fmt.Errorf("not restoring %s: %w", source, err)
+	}
+
+	_, err = os.Stat(destination)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", os.Rename(staged, destination)
+	}
+	if err != nil {
+		os.Remove(staged)
+		return "", err
+	}
+
+	kept := destination + ".before-restore-" + now.UTC().Format(timeLayout)
+
+	_, err = os.Stat(kept)
+	if err == nil {
+		os.Remove(staged)
+		return "", fmt.Errorf("not restoring %s: %s already exists", source, kept)
+	}
+
+	err = os.Rename(destination, kept)
+	if err != nil {
+		os.Remove(staged)
+		return "", err
+	}
+
+	return kept, os.Rename(staged, destination)
+}
+
+// stage copies the backup at source to path, decompressing it if needed.
+func stage(source string, path string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var reader io.Reader = in
+	if strings.HasSuffix(source, compressed) {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+
+		reader = gz
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, reader)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"spire/entry"
+	"spire/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "main.db")
+	backupDir := filepath.Join(dir, "backups")
+
+	store, err := storage.NewSQLiteStorage(databasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	_, err = store.SaveEntry(entry.Entry{Time: time.Now(), Content: "kept", Embedding: embedding(0)})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	path, err := Create(store, backupDir, true, time.Now())
+	if err != nil {
+		t.Fatalf("error creating backup: %v\n", err)
+	}
+
+	if !strings.HasSuffix(path, ".db.gz") {
+		t.Errorf("expected a gzipped backup, got %s", path)
+	}
+
+	_, err = store.SaveEntry(entry.Entry{Time: time.Now(), Content: "lost", Embedding: embedding(1)})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	restoredAt := time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	kept, err := Restore(path, databasePath, restoredAt)
+	if err != nil {
+		t.Fatalf("error restoring backup: %v\n", err)
+	}
+
+	entries, err := store.GetEntries()
+	if err != nil {
+		t.Fatalf("error getting entries: %v\n", err)
+	}
+
+	if len(entries) != 1 || entries[0].Content != "kept" {
+		t.Errorf("expected only the entry from before the backup, got %v", entries)
+	}
+
+	if kept != databasePath+".before-restore-20240601-030000" {
+		t.Errorf("expected the replaced database to be kept with the time, got %s", kept)
+	}
+
+	_, err = os.Stat(kept)
+	if err != nil {
+		t.Errorf("expected the replaced database to be kept: %v", err)
+	}
+
+	// Restoring again in the same second would overwrite the first copy.
+	_, err = Restore(path, databasePath, restoredAt)
+	if err == nil {
+		t.Errorf("expected restoring over an existing copy to fail")
+	}
+
+	again, err := Restore(path, databasePath, restoredAt.Add(time.Hour))
+	if err != nil || again == kept {
+		t.Errorf("expected a second restore to keep another copy, got %s (%v)", again, err)
+	}
+
+	_, err = os.Stat(kept)
+	if err != nil {
+		t.Errorf("expected the first copy to survive a second restore: %v", err)
+	}
+}
+
+func embedding(axis int) entry.Vector {
+	v := make(entry.Vector, 512)
+	v[axis] = 1
+	return v
+}
+
+func TestRestoreRejectsBadBackup(t *testing.T) {
+	dir := t.TempDir()
+	databasePath := filepath.Join(dir, "main.db")
+	backupPath := filepath.Join(dir, "spire-20240101-000000.db")
+
+	err := os.WriteFile(databasePath, []byte("current"), 0o644)
+	if err != nil {
+		t.Fatalf("error writing database: %v\n", err)
+	}
+
+	err = os.WriteFile(backupPath, []byte("not a database"), 0o644)
+	if err != nil {
+		t.Fatalf("error writing backup: %v\n", err)
+	}
+
+	_, err = Restore(backupPath, databasePath, time.Now())
+	if err == nil {
+		t.Fatalf("expected restoring a bad backup to fail")
+	}
+
+	data, _ := os.ReadFile(databasePath)
+	if string(data) != "current" {
+		t.Errorf("expected the database to be left alone, got %q", data)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"spire-20240101-000000.db.gz",
+		"spire-20240102-000000.db",
+		"spire-20240103-000000.db.gz",
+		"spire-20240104-000000.db.partial",
+		"notes.txt",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+		if err != nil {
+			t.Fatalf("error writing file: %v\n", err)
+		}
+	}
+
+	removed, err := Prune(dir, 2)
+	if err != nil {
+		t.Fatalf("error pruning: %v\n", err)
+	}
+
+	if len(removed) != 1 || filepath.Base(removed[0]) != "spire-20240101-000000.db.gz" {
+		t.Errorf("expected only the oldest backup to be removed, got %v", removed)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 4 {
+		t.Errorf("expected other files to be left alone, got %d files", len(files))
+	}
+}
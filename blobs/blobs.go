@@ -0,0 +1,130 @@
+// Package blobs keeps files on disk by the SHA-256 of their contents, so the
+// same file uploaded twice is only stored once.
+package blobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrTooLarge = errors.New("file is too large")
+	ErrNotFound = errors.New("blob not found")
+)
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// path spreads blobs over subfolders named for the first two characters of
+// their hash, so no one folder gets too big.
+func (s *Store) path(hash string) (string, error) {
+	if !hashPattern.MatchString(hash) {
+		return "", ErrNotFound
+	}
+
+	return filepath.Join(s.dir, hash[:2], hash), nil
+}
+
+// Put stores everything read from r and returns its hash and size. It fails
+// with ErrTooLarge, storing nothing, if r has more than limit bytes.
+func (s *Store) Put(r io.Reader, limit int64) (string, int64, error) {
+	temp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(temp, hash), io.LimitReader(r, limit+1))
+	closeErr := temp.Close()
+	if err != nil {
+		return "", 0, err
+	}
+	if closeErr != nil {
+		return "", 0, closeErr
+	}
+
+	if size > limit {
+		return "", 0, ErrTooLarge
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	path, _ := s.path(sum)
+
+	_, err = os.Stat(path)
+	if err == nil {
+		// Already stored. Touch it so Collect treats it as a fresh upload.
+		now := time.Now()
+		return sum, size, os.Chtimes(path, now, now)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return sum, size, os.Rename(temp.Name(), path)
+}
+
+// Open returns the blob with the given hash for reading.
+func (s *Store) Open(hash string) (*os.File, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+// Collect deletes blobs that aren't in keep and are older than minAge, and
+// returns how many it deleted. The age check leaves alone blobs stored for
+// an upload whose entry hasn't been saved yet.
+func (s *Store) Collect(keep map[string]bool, minAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-minAge)
+	removed := 0
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !hashPattern.MatchString(d.Name()) || keep[d.Name()] {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return err
+		}
+
+		err = os.Remove(path)
+		if err == nil {
+			removed++
+		}
+
+		return err
+	})
+
+	return removed, err
+}
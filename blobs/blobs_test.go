@@ -0,0 +1,88 @@
+package blobs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPutAndOpen(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("error creating store: %v\n", err)
+	}
+
+	hash, size, err := store.Put(strings.NewReader("hello"), 10)
+	if err != nil {
+		t.Fatalf("error putting blob: %v\n", err)
+	}
+
+	if hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || size != 5 {
+		t.Errorf("expected the sha256 of hello and a size of 5, got %s and %d", hash, size)
+	}
+
+	again, _, err := store.Put(strings.NewReader("hello"), 10)
+	if err != nil || again != hash {
+		t.Errorf("expected the same content to get the same hash, got %s (%v)", again, err)
+	}
+
+	file, err := store.Open(hash)
+	if err != nil {
+		t.Fatalf("error opening blob: %v\n", err)
+	}
+
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "hello" {
+		t.Errorf("expected hello back, got %q", data)
+	}
+
+	_, _, err = store.Put(strings.NewReader("far too long"), 5)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+
+	for _, hash := range []string{"../../etc/passwd", strings.Repeat("0", 64)} {
+		_, err = store.Open(hash)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for %q, got %v", hash, err)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("error creating store: %v\n", err)
+	}
+
+	kept, _, _ := store.Put(strings.NewReader("kept"), 10)
+	old, _, _ := store.Put(strings.NewReader("old"), 10)
+	fresh, _, _ := store.Put(strings.NewReader("fresh"), 10)
+
+	long := time.Now().Add(-2 * time.Hour)
+	for _, hash := range []string{kept, old} {
+		err := os.Chtimes(filepath.Join(dir, hash[:2], hash), long, long)
+		if err != nil {
+			t.Fatalf("error aging blob: %v\n", err)
+		}
+	}
+
+	removed, err := store.Collect(map[string]bool{kept: true}, time.Hour)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected only the old unreferenced blob to be removed, got %d (%v)", removed, err)
+	}
+
+	for hash, expected := range map[string]bool{kept: true, old: false, fresh: true} {
+		_, err := store.Open(hash)
+		if (err == nil) != expected {
+			t.Errorf("expected %s to exist: %v, got %v", hash, expected, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"spire/calendar"
+	"strconv"
+	"time"
+)
+
+type calendarPage struct {
+	CSRFToken     string
+	Heatmap       calendar.Heatmap
+	Month         calendar.Month
+	Today         time.Time
+	CurrentStreak int
+	LongestStreak int
+	TotalDays     int
+}
+
+func (server *Server) calendarHandler(w http.ResponseWriter, r *http.Request) {
+	today := calendar.Today(server.Location)
+
+	year, month := today.Year(), today.Month()
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "invalid year", http.StatusBadRequest)
+			return
+		}
+
+		year = parsed
+	}
+
+	if value := r.URL.Query().Get("month"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 12 {
+			http.Error(w, "invalid month", http.StatusBadRequest)
+			return
+		}
+
+		month = time.Month(parsed)
+	}
+
+	days, err := server.Storage.GetDailyActivity(server.Location)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	current, longest := calendar.Streaks(days, today)
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "calendar.html", calendarPage{
+		CSRFToken:     csrfToken,
+		Heatmap:       calendar.NewHeatmap(year, days, server.Location),
+		Month:         calendar.NewMonth(year, month, days, server.Location),
+		Today:         today,
+		CurrentStreak: current,
+		LongestStreak: longest,
+		TotalDays:     len(days),
+	})
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// dayEntriesHandler lists the entries written on one day, for clicks on the
+// heatmap and calendar.
+func (server *Server) dayEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	day, err := time.ParseInLocation("2006-01-02", r.PathValue("date"), server.Location)
+	if err != nil {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := server.Storage.GetEntriesBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "entries.html", server.localize(entries))
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"time"
+)
+
+// Day is the writing activity on one calendar day.
+type Day struct {
+	// Midnight at the start of the day.
+	Date    time.Time
+	Entries int
+	Words   int
+}
+
+type dateKey struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func keyOf(t time.Time) dateKey {
+	year, month, day := t.Date()
+	return dateKey{year, month, day}
+}
+
+func index(days []Day) map[dateKey]Day {
+	result := make(map[dateKey]Day, len(days))
+	for _, day := range days {
+		result[keyOf(day.Date)] = day
+	}
+
+	return result
+}
+
+// Streaks returns the number of consecutive days written up to today, and
+// the longest run ever. A streak that ended yesterday still counts as
+// current, since today isn't over yet.
+func Streaks(days []Day, today time.Time) (current int, longest int) {
+	written := index(days)
+
+	run := 0
+	var previous time.Time
+	for _, day := range days {
+		if day.Entries == 0 {
+			continue
+		}
+
+		if !previous.IsZero() && keyOf(previous.AddDate(0, 0, 1)) == keyOf(day.Date) {
+			run++
+		} else {
+			run = 1
+		}
+
+		longest = max(longest, run)
+		previous = day.Date
+	}
+
+	cursor := today
+	if _, ok := written[keyOf(cursor)]; !ok {
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+
+	for {
+		day, ok := written[keyOf(cursor)]
+		if !ok || day.Entries == 0 {
+			break
+		}
+
+		current++
+		cursor = cursor.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
+
+// Level buckets a day's entry count into the five shades of the heatmap.
+func Level(entries int) int {
+	return min(entries, 4)
+}
+
+// Weeks start on Monday.
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,92 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStreaks(t *testing.T) {
+	days := []Day{
+		{Date: date(2025, 1, 1), Entries: 1},
+		{Date: date(2025, 1, 2), Entries: 2},
+		{Date: date(2025, 1, 3), Entries: 1},
+		{Date: date(2025, 1, 10), Entries: 1},
+		{Date: date(2025, 1, 11), Entries: 1},
+	}
+
+	current, longest := Streaks(days, date(2025, 1, 12))
+	if current != 2 || longest != 3 {
+		t.Errorf("expected current 2 and longest 3, got %d and %d", current, longest)
+	}
+
+	current, _ = Streaks(days, date(2025, 1, 11))
+	if current != 2 {
+		t.Errorf("expected a streak including today to be 2, got %d", current)
+	}
+
+	current, _ = Streaks(days, date(2025, 1, 13))
+	if current != 0 {
+		t.Errorf("expected a broken streak to be 0, got %d", current)
+	}
+}
+
+func TestStreaksAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	days := []Day{
+		{Date: time.Date(2025, 3, 8, 0, 0, 0, 0, newYork), Entries: 1},
+		{Date: time.Date(2025, 3, 9, 0, 0, 0, 0, newYork), Entries: 1},
+		{Date: time.Date(2025, 3, 10, 0, 0, 0, 0, newYork), Entries: 1},
+	}
+
+	current, longest := Streaks(days, time.Date(2025, 3, 10, 0, 0, 0, 0, newYork))
+	if current != 3 || longest != 3 {
+		t.Errorf("expected a 3 day streak over the DST change, got %d and %d", current, longest)
+	}
+}
+
+func TestNewHeatmap(t *testing.T) {
+	heatmap := NewHeatmap(2025, []Day{{Date: date(2025, 1, 6), Entries: 7}}, time.UTC)
+
+	if len(heatmap.Cells) != 365 {
+		t.Fatalf("expected 365 cells, got %d", len(heatmap.Cells))
+	}
+
+	if len(heatmap.MonthLabels) != 12 {
+		t.Errorf("expected 12 month labels, got %d", len(heatmap.MonthLabels))
+	}
+
+	// January 1st 2025 is a Wednesday, and January 6th the following Monday.
+	first, monday := heatmap.Cells[0], heatmap.Cells[5]
+	if first.Y <= monday.Y || monday.X <= first.X {
+		t.Errorf("expected Monday to start a new column at the top, got %v and %v", first, monday)
+	}
+
+	if monday.Level != 4 || monday.Entries != 7 {
+		t.Errorf("expected the written day to be at the top level, got %v", monday)
+	}
+}
+
+func TestNewMonth(t *testing.T) {
+	month := NewMonth(2025, time.February, []Day{{Date: date(2025, 2, 14), Entries: 1}}, time.UTC)
+
+	// February 2025 starts on a Saturday and ends on a Friday.
+	if len(month.Weeks) != 5 {
+		t.Fatalf("expected 5 weeks, got %d", len(month.Weeks))
+	}
+
+	if month.Weeks[0][4].InMonth || !month.Weeks[0][5].InMonth {
+		t.Errorf("expected the month to start on the first Saturday")
+	}
+
+	if month.Weeks[2][4].Date.Day() != 14 || month.Weeks[2][4].Entries != 1 {
+		t.Errorf("expected February 14th to have an entry, got %v", month.Weeks[2][4])
+	}
+}
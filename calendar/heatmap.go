@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"time"
+)
+
+// Sizes of the heatmap's SVG, in pixels.
+const (
+	CellSize    = 12
+	cellStep    = CellSize + 2
+	labelHeight = 16
+	labelWidth  = 28
+)
+
+type HeatmapCell struct {
+	Day
+	Level int
+	X     int
+	Y     int
+}
+
+type HeatmapLabel struct {
+	Text string
+	X    int
+	Y    int
+}
+
+// Heatmap is a GitHub-style year of activity: one column per week, one row
+// per weekday.
+type Heatmap struct {
+	Year        int
+	Cells       []HeatmapCell
+	MonthLabels []HeatmapLabel
+	DayLabels   []HeatmapLabel
+	Width       int
+	Height      int
+}
+
+func NewHeatmap(year int, days []Day, loc *time.Location) Heatmap {
+	written := index(days)
+
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	next := first.AddDate(1, 0, 0)
+
+	heatmap := Heatmap{Year: year}
+
+	week := 0
+	for date := first; date.Before(next); date = date.AddDate(0, 0, 1) {
+		weekday := weekdayIndex(date)
+		if weekday == 0 && !date.Equal(first) {
+			week++
+		}
+
+		day, ok := written[keyOf(date)]
+		if !ok {
+			day = Day{Date: date}
+		}
+
+		x := labelWidth + week*cellStep
+		heatmap.Cells = append(heatmap.Cells, HeatmapCell{
+			Day:   day,
+			Level: Level(day.Entries),
+			X:     x,
+			Y:     labelHeight + weekday*cellStep,
+		})
+
+		if date.Day() == 1 {
+			heatmap.MonthLabels = append(heatmap.MonthLabels, HeatmapLabel{
+				Text: date.Format("Jan"),
+				X:    x,
+				Y:    labelHeight - 4,
+			})
+		}
+	}
+
+	for _, weekday := range []int{0, 2, 4} {
+		heatmap.DayLabels = append(heatmap.DayLabels, HeatmapLabel{
+			Text: first.AddDate(0, 0, weekday-weekdayIndex(first)).Format("Mon"),
+			X:    0,
+			Y:    labelHeight + weekday*cellStep + CellSize - 2,
+		})
+	}
+
+	heatmap.Width = labelWidth + (week+1)*cellStep
+	heatmap.Height = labelHeight + 7*cellStep
+
+	return heatmap
+}
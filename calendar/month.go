@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"time"
+)
+
+type MonthCell struct {
+	Day
+	// False for the padding before the first and after the last day.
+	InMonth bool
+	Level   int
+}
+
+// Month is a month laid out as Monday-to-Sunday weeks.
+type Month struct {
+	First    time.Time
+	Weeks    [][]MonthCell
+	Previous time.Time
+	Next     time.Time
+}
+
+func NewMonth(year int, month time.Month, days []Day, loc *time.Location) Month {
+	written := index(days)
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	next := first.AddDate(0, 1, 0)
+
+	result := Month{
+		First:    first,
+		Previous: first.AddDate(0, -1, 0),
+		Next:     next,
+	}
+
+	var week []MonthCell
+	for i := 0; i < weekdayIndex(first); i++ {
+		week = append(week, MonthCell{})
+	}
+
+	for date := first; date.Before(next); date = date.AddDate(0, 0, 1) {
+		day, ok := written[keyOf(date)]
+		if !ok {
+			day = Day{Date: date}
+		}
+
+		week = append(week, MonthCell{Day: day, InMonth: true, Level: Level(day.Entries)})
+
+		if len(week) == 7 {
+			result.Weeks = append(result.Weeks, week)
+			week = nil
+		}
+	}
+
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, MonthCell{})
+		}
+
+		result.Weeks = append(result.Weeks, week)
+	}
+
+	return result
+}
+
+// Today returns midnight today in loc, for comparing against day dates.
+func Today(loc *time.Location) time.Time {
+	return startOfDay(time.Now().In(loc))
+}
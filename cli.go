@@ -0,0 +1,357 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"spire/backup"
+	"spire/exporter"
+	"spire/importer"
+	"spire/storage"
+	"spire/token"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: spire [command]
+
+With no command, spire starts the web server.
+
+commands:
+  token create -name NAME [-scopes read,write,search] [-days N]
+  token list
+  token revoke ID
+  import markdown [-dry-run] DIR
+  import dayone [-dry-run] ZIP
+  export -format jsonl [-embeddings] [-private] [-o FILE]
+  export -format markdown|html [-private] -o DIR|ZIP
+  backup [-gzip] [-keep N] DIR
+  restore FILE
+  encrypt
+  rekey`
+
+func runCommand(store *storage.SQLiteStorage, args []string) error {
+	switch args[0] {
+	case "token":
+		return tokenCommand(store, args[1:])
+	case "import":
+		return importCommand(store, args[1:])
+	case "export":
+		return exportCommand(store, args[1:])
+	case "backup":
+		return backupCommand(store, args[1:])
+	case "encrypt":
+		return encryptCommand(store, args[1:])
+	case "rekey":
+		return rekeyCommand(store, args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
+
+func tokenCommand(store *storage.SQLiteStorage, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("token create", flag.ExitOnError)
+		name := flags.String("name", "", "a name to recognize the token by")
+		scopes := flags.String("scopes", "read,search", "comma-separated scopes: read, write, search")
+		days := flags.Int("days", 0, "days until the token expires; 0 never expires")
+		flags.Parse(args[1:])
+
+		parsedScopes, err := token.ParseScopes(*scopes)
+		if err != nil {
+			return err
+		}
+
+		t, plaintext, err := createToken(store, *name, parsedScopes, time.Duration(*days)*24*time.Hour)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("created token %d (%s)\n", t.ID, t.Name)
+		fmt.Println("store it somewhere safe, it won't be shown again:")
+		fmt.Println(plaintext)
+		return nil
+
+	case "list":
+		tokens, err := store.GetTokens()
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSCOPES\tCREATED\tLAST USED\tEXPIRES")
+		for _, t := range tokens {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				t.ID,
+				t.Name,
+				token.FormatScopes(t.Scopes),
+				t.CreatedAt.Format("2006-01-02 15:04"),
+				formatOptionalTime(t.LastUsedAt, "never"),
+				formatOptionalTime(t.ExpiresAt, "never"),
+			)
+		}
+		return tw.Flush()
+
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: spire token revoke ID")
+		}
+
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid token id %q", args[1])
+		}
+
+		err = store.RevokeToken(id)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("revoked token %d\n", id)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown token command %q\n\n%s", args[0], usage)
+	}
+}
+
+func importCommand(store *storage.SQLiteStorage, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet("import "+args[0], flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without saving anything")
+	flags.Parse(args[1:])
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: spire import %s [-dry-run] PATH", args[0])
+	}
+
+	location, err := journalLocation()
+	if err != nil {
+		return err
+	}
+
+	report := importer.Report{DryRun: *dryRun}
+	var notes []importer.Note
+
+	switch args[0] {
+	case "markdown":
+		notes, err = importer.ReadMarkdownDir(flags.Arg(0), location, &report)
+	case "dayone":
+		notes, err = importer.ReadDayOne(flags.Arg(0), location, &report)
+	default:
+		return fmt.Errorf("unknown import format %q\n\n%s", args[0], usage)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("read %d notes\n", len(notes))
+
+	err = importNotes(store, notes, &report, func(done, total int) {
+		fmt.Printf("queued %d of %d\n", done, total)
+	})
+
+	report.Print(os.Stdout)
+	if err == nil && !report.DryRun && report.Imported > 0 {
+		fmt.Println("entries appear in the journal as spire embeds them in the background")
+	}
+
+	return err
+}
+
+func exportCommand(store *storage.SQLiteStorage, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", exportJSONL, "jsonl, markdown or html")
+	embeddings := flags.Bool("embeddings", false, "include each entry's embedding in jsonl")
+	output := flags.String("o", "", "where to write the export; jsonl defaults to stdout")
+	private := flags.Bool("private", false, "include private entries")
+	flags.Parse(args)
+
+	location, err := journalLocation()
+	if err != nil {
+		return err
+	}
+
+	entries := exportEntries(store, location, *private)
+
+	if *format == exportJSONL {
+		if *output == "" {
+			return exporter.WriteJSONL(os.Stdout, entries, *embeddings)
+		}
+
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+
+		err = exporter.WriteJSONL(file, entries, *embeddings)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		return file.Close()
+	}
+
+	if *output == "" {
+		return fmt.Errorf("usage: spire export -format %s -o DIR|ZIP", *format)
+	}
+
+	if strings.HasSuffix(*output, ".zip") {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		archive := zip.NewWriter(file)
+
+		err = exportArchive(archive, entries, *format, location)
+		if err != nil {
+			return err
+		}
+
+		err = archive.Close()
+		if err != nil {
+			return err
+		}
+
+		return file.Close()
+	}
+
+	archive := exporter.NewDirArchive(*output)
+
+	err = exportArchive(archive, entries, *format, location)
+	if err != nil {
+		archive.Close()
+		return err
+	}
+
+	return archive.Close()
+}
+
+func backupCommand(store *storage.SQLiteStorage, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	compress := flags.Bool("gzip", false, "compress the backup")
+	keep := flags.Int("keep", 0, "delete all but this many of the newest backups in DIR; 0 keeps all")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: spire backup [-gzip] [-keep N] DIR")
+	}
+
+	path, removed, err := takeBackup(store, flags.Arg(0), *compress, *keep)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s\n", path)
+	for _, old := range removed {
+		fmt.Printf("removed %s\n", old)
+	}
+
+	return nil
+}
+
+// restoreCommand runs without the database open, so it works when main.db is
+// damaged or can't be unlocked.
+func restoreCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: spire restore FILE")
+	}
+
+	if os.Getenv("SPIRE_PRIMARY_URL") != "" {
+		return errors.New("main.db is a replica of SPIRE_PRIMARY_URL; restore the primary instead")
+	}
+
+	kept, err := backup.Restore(args[0], databasePath, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored %s from %s\n", databasePath, args[0])
+	if kept != "" {
+		fmt.Printf("the previous database is in %s\n", kept)
+	}
+
+	return nil
+}
+
+func encryptCommand(store *storage.SQLiteStorage, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: spire encrypt")
+	}
+
+	passphrase := os.Getenv("SPIRE_PASSPHRASE")
+	if passphrase == "" {
+		return errors.New("set SPIRE_PASSPHRASE to the passphrase to encrypt with")
+	}
+
+	err := store.Encrypt(passphrase)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("encrypted %s; keep SPIRE_PASSPHRASE set to open it\n", databasePath)
+	fmt.Println("backups taken before now are not encrypted")
+	return nil
+}
+
+// rekeyCommand reads the new passphrase from standard input rather than an
+// argument, so it stays out of shell history and the process list.
+func rekeyCommand(store *storage.SQLiteStorage, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: spire rekey")
+	}
+
+	fmt.Fprint(os.Stderr, "new passphrase (empty to keep the current one): ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	passphrase := os.Getenv("SPIRE_PASSPHRASE")
+	newPassphrase := strings.TrimRight(line, "\r\n")
+	if newPassphrase == "" {
+		newPassphrase = passphrase
+	}
+
+	err = store.Rekey(passphrase, newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	if newPassphrase == passphrase {
+		fmt.Printf("rekeyed %s with the same passphrase\n", databasePath)
+	} else {
+		fmt.Printf("rekeyed %s; set SPIRE_PASSPHRASE to the new passphrase\n", databasePath)
+	}
+
+	return nil
+}
+
+func formatOptionalTime(t *time.Time, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+
+	return t.Format("2006-01-02 15:04")
+}
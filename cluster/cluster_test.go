@@ -0,0 +1,71 @@
+package cluster
+
+import (
+	"slices"
+	"spire/entry"
+	"testing"
+)
+
+func TestKMeans(t *testing.T) {
+	vectors := []entry.Vector{
+		{1, 0.1, 0},
+		{0.9, 0, 0.1},
+		{1, 0, 0},
+		{0, 1, 0.1},
+		{0.1, 0.9, 0},
+		{0, 1, 0},
+	}
+
+	result := KMeans(vectors, 2, 42, 20)
+
+	if len(result.Centroids) != 2 {
+		t.Fatalf("expected 2 centroids, got %d", len(result.Centroids))
+	}
+
+	first, second := result.Assignments[0], result.Assignments[3]
+	if first == second {
+		t.Fatalf("expected the two groups in different clusters, got %v", result.Assignments)
+	}
+
+	expected := []int{first, first, first, second, second, second}
+	if !slices.Equal(expected, result.Assignments) {
+		t.Errorf("expected %v, got %v", expected, result.Assignments)
+	}
+
+	again := KMeans(vectors, 2, 42, 20)
+	if !slices.Equal(result.Assignments, again.Assignments) {
+		t.Errorf("expected the same seed to give the same clusters")
+	}
+}
+
+func TestChooseK(t *testing.T) {
+	for _, test := range []struct{ n, expected int }{
+		{3, 0},
+		{4, 2},
+		{50, 5},
+		{10000, 12},
+	} {
+		if actual := ChooseK(test.n); actual != test.expected {
+			t.Errorf("expected %d clusters for %d entries, got %d", test.expected, test.n, actual)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	contents := []string{
+		"garden tomatoes watering garden",
+		"tomatoes in the garden",
+		"meeting about the deadline at work",
+		"work deadline stress",
+	}
+
+	keywords := Keywords(contents, []int{0, 0, 1, 1}, 2, 2)
+
+	if !slices.Equal(keywords[0], []string{"garden", "tomatoes"}) {
+		t.Errorf("expected garden and tomatoes, got %v", keywords[0])
+	}
+
+	if !slices.Equal(keywords[1], []string{"deadline", "work"}) {
+		t.Errorf("expected deadline and work, got %v", keywords[1])
+	}
+}
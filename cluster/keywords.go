@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"math"
+	"sort"
+	"spire/stats"
+)
+
+// Keywords labels each cluster with the terms that are frequent in it but
+// rare in the others, scored like TF-IDF with clusters as documents.
+func Keywords(contents []string, assignments []int, k int, limit int) [][]string {
+	counts := make([]map[string]int, k)
+	totals := make([]int, k)
+	for cluster := range counts {
+		counts[cluster] = map[string]int{}
+	}
+
+	for i, content := range contents {
+		cluster := assignments[i]
+		for _, term := range stats.Terms(content) {
+			counts[cluster][term]++
+			totals[cluster]++
+		}
+	}
+
+	clustersWithTerm := map[string]int{}
+	for _, clusterCounts := range counts {
+		for term := range clusterCounts {
+			clustersWithTerm[term]++
+		}
+	}
+
+	result := make([][]string, k)
+	for cluster, clusterCounts := range counts {
+		type scored struct {
+			term  string
+			score float64
+		}
+
+		var scores []scored
+		for term, count := range clusterCounts {
+			// A term used once is as likely to be noise as a theme.
+			if count < 2 && totals[cluster] > 20 {
+				continue
+			}
+
+			frequency := float64(count) / float64(totals[cluster])
+			rarity := math.Log(1 + float64(k)/float64(clustersWithTerm[term]))
+			scores = append(scores, scored{term, frequency * rarity})
+		}
+
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].score != scores[j].score {
+				return scores[i].score > scores[j].score
+			}
+
+			return scores[i].term < scores[j].term
+		})
+
+		for i := 0; i < len(scores) && i < limit; i++ {
+			result[cluster] = append(result[cluster], scores[i].term)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"spire/entry"
+)
+
+// Result of clustering n vectors into k groups.
+type Result struct {
+	// Assignments[i] is the cluster of the i-th vector.
+	Assignments []int
+	// Unit-length centroids, one per cluster.
+	Centroids []entry.Vector
+}
+
+// ChooseK picks a number of clusters for n entries: roughly sqrt(n/2),
+// which grows slowly enough to keep topics broad.
+func ChooseK(n int) int {
+	if n < 4 {
+		return 0
+	}
+
+	return min(max(2, int(math.Round(math.Sqrt(float64(n)/2)))), 12)
+}
+
+// KMeans runs spherical k-means: vectors and centroids are compared by
+// cosine similarity, which is what the embeddings are meant for. The seed
+// keeps results stable between runs over the same entries.
+func KMeans(vectors []entry.Vector, k int, seed int64, maxIterations int) Result {
+	if k <= 0 || len(vectors) == 0 {
+		return Result{}
+	}
+
+	k = min(k, len(vectors))
+
+	normalized := make([]entry.Vector, len(vectors))
+	for i, v := range vectors {
+		normalized[i] = v.Normalized()
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	centroids := initialCentroids(normalized, k, random)
+	assignments := make([]int, len(normalized))
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+		for i, v := range normalized {
+			nearest, _ := Nearest(centroids, v)
+			if iteration == 0 || nearest != assignments[i] {
+				changed = true
+			}
+
+			assignments[i] = nearest
+		}
+
+		if !changed {
+			break
+		}
+
+		members := make([][]entry.Vector, k)
+		for i, cluster := range assignments {
+			members[cluster] = append(members[cluster], normalized[i])
+		}
+
+		for cluster := range centroids {
+			// An emptied cluster keeps its old centroid rather than
+			// disappearing.
+			if len(members[cluster]) > 0 {
+				centroids[cluster] = entry.Mean(members[cluster]).Normalized()
+			}
+		}
+	}
+
+	return Result{Assignments: assignments, Centroids: centroids}
+}
+
+// initialCentroids uses k-means++: each new centroid is picked with
+// probability proportional to its distance from the nearest existing one,
+// which spreads them out.
+func initialCentroids(vectors []entry.Vector, k int, random *rand.Rand) []entry.Vector {
+	centroids := []entry.Vector{vectors[random.Intn(len(vectors))]}
+
+	distances := make([]float64, len(vectors))
+	for len(centroids) < k {
+		total := 0.0
+		for i, v := range vectors {
+			_, similarity := Nearest(centroids, v)
+			distance := 1 - similarity
+			distances[i] = distance * distance
+			total += distances[i]
+		}
+
+		// Everything left sits on top of an existing centroid.
+		if total == 0 {
+			break
+		}
+
+		target := random.Float64() * total
+		chosen := len(vectors) - 1
+		for i, distance := range distances {
+			target -= distance
+			if target <= 0 {
+				chosen = i
+				break
+			}
+		}
+
+		centroids = append(centroids, vectors[chosen])
+	}
+
+	return centroids
+}
+
+// Nearest returns the index of the centroid most similar to v, and that
+// similarity.
+func Nearest(centroids []entry.Vector, v entry.Vector) (int, float64) {
+	best, bestSimilarity := -1, math.Inf(-1)
+	for i, centroid := range centroids {
+		similarity := centroid.CosineSimilarity(v)
+		if similarity > bestSimilarity {
+			best, bestSimilarity = i, similarity
+		}
+	}
+
+	return best, bestSimilarity
+}
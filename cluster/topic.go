@@ -0,0 +1,31 @@
+package cluster
+
+import (
+	"spire/entry"
+	"strings"
+)
+
+// A Topic is a cluster of entries stored between runs.
+type Topic struct {
+	ID       int64
+	Keywords []string
+	Centroid entry.Vector
+	Size     int
+}
+
+// Label is a short name for the topic made from its top keywords.
+func (t Topic) Label() string {
+	if len(t.Keywords) == 0 {
+		return "Untitled"
+	}
+
+	return strings.Join(t.Keywords[:min(3, len(t.Keywords))], " · ")
+}
+
+// Assignment places one entry in a topic.
+type Assignment struct {
+	EntryID int64
+	// Index into the topics being saved, or the topic ID once stored.
+	Topic      int64
+	Similarity float64
+}
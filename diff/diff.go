@@ -0,0 +1,150 @@
+// Package diff compares two texts word by word.
+package diff
+
+import (
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// A Change is a run of text that's in both texts, only in the new one, or
+// only in the old one.
+type Change struct {
+	Op   Op
+	Text string
+}
+
+// Words returns the changes that turn before into after. Words and the
+// whitespace between them are compared as separate tokens, so a change in
+// spacing doesn't mark the words around it as changed.
+func Words(before string, after string) []Change {
+	a := tokenize(before)
+	b := tokenize(after)
+
+	// Only the middle that differs needs the quadratic comparison.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var changes []Change
+	changes = appendChange(changes, Equal, a[:prefix]...)
+	changes = appendChanges(changes, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	changes = appendChange(changes, Equal, a[len(a)-suffix:]...)
+
+	return changes
+}
+
+// appendChanges diffs a and b with a longest common subsequence table.
+func appendChanges(changes []Change, a []string, b []string) []Change {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lengths := make([][]int32, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			changes = appendChange(changes, Equal, a[i])
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			changes = appendChange(changes, Delete, a[i])
+			i++
+		default:
+			changes = appendChange(changes, Insert, b[j])
+			j++
+		}
+	}
+
+	changes = appendChange(changes, Delete, a[i:]...)
+	changes = appendChange(changes, Insert, b[j:]...)
+
+	return changes
+}
+
+// appendChange adds tokens to the last change if it has the same op, so runs
+// of changed words come out as one change.
+func appendChange(changes []Change, op Op, tokens ...string) []Change {
+	if len(tokens) == 0 {
+		return changes
+	}
+
+	text := strings.Join(tokens, "")
+
+	if len(changes) > 0 && changes[len(changes)-1].Op == op {
+		changes[len(changes)-1].Text += text
+		return changes
+	}
+
+	return append(changes, Change{Op: op, Text: text})
+}
+
+// tokenize splits text into alternating runs of whitespace and non-space.
+func tokenize(text string) []string {
+	var tokens []string
+
+	start := 0
+	space := false
+	for i, r := range text {
+		if i > start && unicode.IsSpace(r) != space {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+
+		space = unicode.IsSpace(r)
+	}
+
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
+
+// HTML renders changes as escaped text with insertions in <ins> and
+// deletions in <del>, for showing inside a <pre> or similar.
+func HTML(changes []Change) template.HTML {
+	var b strings.Builder
+
+	for _, change := range changes {
+		text := template.HTMLEscapeString(change.Text)
+
+		switch change.Op {
+		case Insert:
+			b.WriteString("<ins>" + text + "</ins>")
+		case Delete:
+			b.WriteString("<del>" + text + "</del>")
+		default:
+			b.WriteString(text)
+		}
+	}
+
+	return template.HTML(b.String())
+}
@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		before   string
+		after    string
+		expected []Change
+	}{
+		{
+			name:     "unchanged",
+			before:   "planted tomatoes",
+			after:    "planted tomatoes",
+			expected: []Change{{Equal, "planted tomatoes"}},
+		},
+		{
+			name:   "replaced word",
+			before: "planted red tomatoes today",
+			after:  "planted green tomatoes today",
+			expected: []Change{
+				{Equal, "planted "},
+				{Delete, "red"},
+				{Insert, "green"},
+				{Equal, " tomatoes today"},
+			},
+		},
+		{
+			name:   "added sentence",
+			before: "Long day.",
+			after:  "Long day. Slept early.",
+			expected: []Change{
+				{Equal, "Long day."},
+				{Insert, " Slept early."},
+			},
+		},
+		{
+			name:     "from nothing",
+			before:   "",
+			after:    "hello there",
+			expected: []Change{{Insert, "hello there"}},
+		},
+		{
+			name:   "removed words in the middle",
+			before: "one two three four five",
+			after:  "one five",
+			expected: []Change{
+				{Equal, "one "},
+				{Delete, "two three four "},
+				{Equal, "five"},
+			},
+		},
+		{
+			name:   "non-ascii",
+			before: "café au lait",
+			after:  "café noir",
+			expected: []Change{
+				{Equal, "café "},
+				{Delete, "au lait"},
+				{Insert, "noir"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			changes := Words(test.before, test.after)
+			if !reflect.DeepEqual(changes, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, changes)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	html := HTML([]Change{{Equal, "a <b> "}, {Delete, "old"}, {Insert, "new & improved"}})
+
+	expected := "a &lt;b&gt; <del>old</del><ins>new &amp; improved</ins>"
+	if string(html) != expected {
+		t.Errorf("expected %q, got %q", expected, html)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"spire/entry"
+	"spire/storage"
+	"strings"
+	"time"
+)
+
+const (
+	// How far back to look for the note being pasted again.
+	duplicateWindow = 30 * 24 * time.Hour
+	// Cosine similarity above which a new entry is probably a repeat.
+	defaultDuplicateThreshold = 0.95
+)
+
+type duplicateWarning struct {
+	Content  string
+	Tags     string
+	Existing entry.Entry
+	// Similarity as a whole percentage, for display.
+	Percent int
+}
+
+// findDuplicate looks for a recent entry that's nearly the same as one with
+// the given embedding.
+func (server *Server) findDuplicate(embedding entry.Vector) (entry.Entry, float64, bool, error) {
+	if server.DuplicateThreshold <= 0 {
+		return entry.Entry{}, 0, false, nil
+	}
+
+	existing, similarity, err := server.Storage.GetNearestRecentEntry(embedding, time.Now().Add(-duplicateWindow))
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		return entry.Entry{}, 0, false, nil
+	}
+	if err != nil {
+		return entry.Entry{}, 0, false, err
+	}
+
+	return existing, similarity, similarity >= server.DuplicateThreshold, nil
+}
+
+// renderDuplicateWarning shows the near-duplicate next to the form instead of
+// adding anything to the list of entries.
+func (server *Server) renderDuplicateWarning(w http.ResponseWriter, warning duplicateWarning) {
+	warning.Existing.Time = warning.Existing.Time.In(server.Location)
+
+	w.Header().Set("HX-Retarget", "#duplicate-warning")
+	w.Header().Set("HX-Reswap", "innerHTML")
+
+	err := templates.ExecuteTemplate(w, "duplicate-warning", warning)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// mergeEntryHandler folds a would-be new entry into an existing one: its
+// content is appended as a new paragraph and its tags are added. A hidden
+// entry can't be merged into.
+func (server *Server) mergeEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.ParseForm()
+
+	existing, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if server.hidden(r, existing) {
+		http.Error(w, "reveal the entry before merging into it", http.StatusForbidden)
+		return
+	}
+
+	merged := existing
+	merged.Content = entry.MergeContent(existing.Content, r.PostForm.Get("entry"))
+	merged.Tags = entry.MergeTags(existing.Tags, entry.ParseTagList(r.PostForm.Get("tags")))
+
+	// A repeated paste leaves the content as it was, so the embedding can
+	// stay too.
+	if merged.Content != existing.Content {
+		merged.Embedding, err = server.VoyageClient.GetEmbedding(merged.Content)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = server.Storage.UpdateEntry(merged)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.TopicsJob.Trigger()
+	server.MapJob.Trigger()
+
+	merged, err = server.Storage.GetEntry(id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	merged.Time = merged.Time.In(server.Location)
+	err = templates.ExecuteTemplate(w, "merged-entry", merged)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderTagCloudOOB(w, r)
+}
+
+func newDuplicateWarning(content string, tags []string, existing entry.Entry, similarity float64) duplicateWarning {
+	return duplicateWarning{
+		Content:  content,
+		Tags:     strings.Join(tags, ", "),
+		Existing: existing,
+		Percent:  int(math.Round(similarity * 100)),
+	}
+}
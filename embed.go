@@ -0,0 +1,54 @@
+package main
+
+import (
+	"spire/voyage"
+	"time"
+)
+
+// How often to look for queued entries without being asked, which picks up
+// imports run from the command line and retries after API errors.
+const embedInterval = time.Minute
+
+// embedPending embeds queued entries a batch at a time and saves them as
+// real entries, until the queue is empty.
+func (server *Server) embedPending() error {
+	promoted := 0
+	defer func() {
+		if promoted > 0 {
+			server.TopicsJob.Trigger()
+			server.MapJob.Trigger()
+		}
+	}()
+
+	for {
+		pending, err := server.Storage.GetPendingEntries(voyage.MaxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		contents := make([]string, len(pending))
+		for i, e := range pending {
+			contents[i] = e.Content
+		}
+
+		embeddings, err := server.VoyageClient.GetEmbeddings(contents)
+		if err != nil {
+			return err
+		}
+
+		for i := range pending {
+			pending[i].Embedding = embeddings[i]
+		}
+
+		_, err = server.Storage.PromotePendingEntries(pending)
+		if err != nil {
+			return err
+		}
+
+		promoted += len(pending)
+	}
+}
@@ -0,0 +1,19 @@
+package entry
+
+import "strings"
+
+// An Attachment is a file uploaded with an entry. The file itself lives in
+// the blob store under its hash.
+type Attachment struct {
+	ID          int64
+	Name        string
+	ContentType string
+	Size        int64
+	Hash        string
+	// Empty if no thumbnail could be made, as for anything but images.
+	ThumbnailHash string
+}
+
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
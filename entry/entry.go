@@ -10,9 +10,37 @@ import (
 type Vector []float32
 
 type Entry struct {
-	Time      time.Time
-	Content   string
-	Embedding Vector
+	ID          int64
+	Time        time.Time
+	Content     string
+	Embedding   Vector
+	Tags        []string
+	Links       []Link
+	Attachments []Attachment
+	// Private entries are shown as a placeholder until the password is
+	// entered again.
+	Private bool
+	// Revealed is set on a private entry that's about to be shown in full.
+	// It's never stored.
+	Revealed bool
+	// Zero puts a new entry in the default notebook.
+	NotebookID int64
+}
+
+// Hidden reports whether the entry should be shown as a placeholder.
+func (e Entry) Hidden() bool {
+	return e.Private && !e.Revealed
+}
+
+// MergeContent appends addition to existing as a new paragraph, unless it's
+// already in there, as when the same note is pasted twice.
+func MergeContent(existing string, addition string) string {
+	addition = strings.TrimSpace(addition)
+	if addition == "" || strings.Contains(existing, addition) {
+		return existing
+	}
+
+	return strings.TrimRight(existing, "\n") + "\n\n" + addition
 }
 
 // string [1,2,3] -> floats [1, 2, 3]
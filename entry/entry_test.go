@@ -1,6 +1,7 @@
 package entry
 
 import (
+	"math"
 	"slices"
 	"testing"
 )
@@ -33,3 +34,87 @@ func TestSerializeEmbeddingsVector(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, actual)
 	}
 }
+
+func TestExtractTags(t *testing.T) {
+	actual := ExtractTags("#Go and #htmx, again #go.\n# Heading\nissue#12 #1 (#side-project)")
+	expected := []string{"go", "htmx", "side-project"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestParseTagList(t *testing.T) {
+	actual := ParseTagList("work, #Ideas  side-project,,work")
+	expected := []string{"ideas", "side-project", "work"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	actual := Title("\n\n## Morning pages  \nsome text")
+	expected := "Morning pages"
+	if expected != actual {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestMergeContent(t *testing.T) {
+	actual := MergeContent("walked the dog\n", "  then coffee ")
+	expected := "walked the dog\n\nthen coffee"
+	if expected != actual {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	actual = MergeContent("walked the dog", "walked the dog")
+	if actual != "walked the dog" {
+		t.Errorf("expected a repeated paste to be dropped, got %q", actual)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	actual := ExtractLinks("see [[12]] and [[ Morning pages ]], then [[12]] again, not [[]] or [[a\nb]]")
+	expected := []string{"12", "Morning pages"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestReplaceLinks(t *testing.T) {
+	actual := ReplaceLinks("see [[12]] and [[Nowhere]]", map[string]string{"12": "/entries/12"})
+	expected := "see [12](/entries/12) and [[Nowhere]]"
+	if expected != actual {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	a := Vector{1, 0}
+
+	for _, test := range []struct {
+		other    Vector
+		expected float64
+	}{
+		{Vector{2, 0}, 1},
+		{Vector{0, 3}, 0},
+		{Vector{-1, 0}, -1},
+		{Vector{0, 0}, 0},
+	} {
+		actual := a.CosineSimilarity(test.other)
+		if math.Abs(actual-test.expected) > 1e-9 {
+			t.Errorf("expected %f for %v, got %f", test.expected, test.other, actual)
+		}
+	}
+}
+
+func TestMean(t *testing.T) {
+	actual := Mean([]Vector{{1, 2}, {3, 4}})
+	expected := Vector{2, 3}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if math.Abs(actual.Normalized().Norm()-1) > 1e-6 {
+		t.Errorf("expected a normalized vector to have unit length")
+	}
+}
@@ -0,0 +1,79 @@
+package entry
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A resolved or dangling [[link]] from one entry to another.
+type Link struct {
+	Label string
+	// Zero if no entry matched the label when it was last resolved.
+	TargetID int64
+}
+
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// Title is the first non-empty line of the content, without any markdown
+// heading markers. It's what [[Title]] links are matched against.
+func Title(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// TitleKey is what titles and [[Title]] labels are matched by, so a link
+// finds its entry whatever the case, in any script.
+func TitleKey(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
+
+// "see [[12]] and [[Morning pages]]" -> [12 Morning pages]
+func ExtractLinks(content string) []string {
+	var labels []string
+	seen := map[string]bool{}
+
+	for _, match := range linkPattern.FindAllStringSubmatch(content, -1) {
+		label := strings.TrimSpace(match[1])
+		if label == "" || seen[label] {
+			continue
+		}
+
+		seen[label] = true
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+// LinkID returns the entry ID a label refers to directly, as in [[12]].
+func LinkID(label string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(label), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// ReplaceLinks turns [[links]] into markdown links using the given hrefs,
+// keyed by label. Links without an href are left as they are.
+func ReplaceLinks(content string, hrefs map[string]string) string {
+	return linkPattern.ReplaceAllStringFunc(content, func(match string) string {
+		label := strings.TrimSpace(match[2 : len(match)-2])
+
+		href, ok := hrefs[label]
+		if !ok {
+			return match
+		}
+
+		return "[" + label + "](" + href + ")"
+	})
+}
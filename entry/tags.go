@@ -0,0 +1,66 @@
+package entry
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// A hashtag starts at the beginning of the content or after whitespace or
+// punctuation, so "#go" matches but "issue#12", "a&#39;" and "# Heading"
+// don't.
+var hashtagPattern = regexp.MustCompile(`(?:^|[\s(\[{,.;:!?"'])#([\p{L}\p{N}_][\p{L}\p{N}_-]*)`)
+
+// "#Go and #htmx, again #go" -> [go htmx]
+func ExtractTags(content string) []string {
+	var tags []string
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tag := NormalizeTag(match[1])
+
+		// "#1" is a number, not a tag.
+		if !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return MergeTags(tags)
+}
+
+// " #Work " -> "work"
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimLeft(tag, "#")
+	tag = strings.TrimRight(tag, "-")
+
+	return strings.ToLower(tag)
+}
+
+// "work, #Ideas side-project" -> [ideas side-project work]
+func ParseTagList(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	return MergeTags(fields)
+}
+
+// MergeTags normalizes, dedupes and sorts the given tag lists.
+func MergeTags(lists ...[]string) []string {
+	var merged []string
+
+	for _, list := range lists {
+		for _, tag := range list {
+			tag = NormalizeTag(tag)
+			if tag != "" {
+				merged = append(merged, tag)
+			}
+		}
+	}
+
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
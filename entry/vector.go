@@ -0,0 +1,64 @@
+package entry
+
+import "math"
+
+func (v Vector) Dot(other Vector) float64 {
+	var sum float64
+	for i := range min(len(v), len(other)) {
+		sum += float64(v[i]) * float64(other[i])
+	}
+
+	return sum
+}
+
+func (v Vector) Norm() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// CosineSimilarity is 1 for vectors pointing the same way, 0 for unrelated
+// ones and -1 for opposites. It's 0 if either vector is all zeros.
+func (v Vector) CosineSimilarity(other Vector) float64 {
+	norms := v.Norm() * other.Norm()
+	if norms == 0 {
+		return 0
+	}
+
+	return v.Dot(other) / norms
+}
+
+// Normalized returns a copy of v scaled to unit length.
+func (v Vector) Normalized() Vector {
+	result := make(Vector, len(v))
+
+	norm := v.Norm()
+	if norm == 0 {
+		return result
+	}
+
+	for i := range v {
+		result[i] = float32(float64(v[i]) / norm)
+	}
+
+	return result
+}
+
+// Mean averages vectors of the same length. It returns nil for no vectors.
+func Mean(vectors []Vector) Vector {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	sums := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i := range sums {
+			sums[i] += float64(v[i])
+		}
+	}
+
+	result := make(Vector, len(sums))
+	for i, sum := range sums {
+		result[i] = float32(sum / float64(len(vectors)))
+	}
+
+	return result
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"spire/entry"
+	"spire/exporter"
+	"spire/storage"
+	"time"
+)
+
+const (
+	exportJSONL    = "jsonl"
+	exportMarkdown = "markdown"
+	exportHTML     = "html"
+)
+
+// siteTemplates render the static site export. They share entry.html with
+// the app, but link entries to each other's files and leave out anything
+// that needs the server or the network.
+var siteTemplates = template.Must(template.New("").Funcs(templateFuncs).Funcs(template.FuncMap{
+	"entryContent": entryContentRenderer(exporter.SiteEntryURL),
+	"entryURL":     exporter.SiteEntryURL,
+	"interactive":  func() bool { return false },
+}).ParseFiles(
+	"templates/export/site.html",
+	"templates/export/site.css",
+	"templates/components/entry.html",
+))
+
+// exportEntries walks the whole journal, oldest first, with times in the
+// journal's zone. When private entries are left out, so are links to them,
+// which are written as if they didn't match anything.
+func exportEntries(store *storage.SQLiteStorage, loc *time.Location, includePrivate bool) exporter.Entries {
+	return func(fn func(entry.Entry) error) error {
+		private := map[int64]bool{}
+		if !includePrivate {
+			var err error
+			private, err = store.GetPrivateEntryIDs()
+			if err != nil {
+				return err
+			}
+		}
+
+		return store.ForEachEntry(func(e entry.Entry) error {
+			if e.Private {
+				if !includePrivate {
+					return nil
+				}
+
+				e.Revealed = true
+			}
+
+			for i, link := range e.Links {
+				if private[link.TargetID] {
+					e.Links[i].TargetID = 0
+				}
+			}
+
+			e.Time = e.Time.In(loc)
+			return fn(e)
+		})
+	}
+}
+
+// exportArchive writes the multi-file formats, markdown and html.
+func exportArchive(archive exporter.Archive, entries exporter.Entries, format string, loc *time.Location) error {
+	switch format {
+	case exportMarkdown:
+		return exporter.WriteMarkdown(archive, entries)
+	case exportHTML:
+		return exporter.WriteSite(archive, entries, siteTemplates, loc)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// exportHandler streams an export as it's written: JSON Lines directly, and
+// the other formats as a zip.
+func (server *Server) exportHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportJSONL
+	}
+
+	name := "spire-" + time.Now().In(server.Location).Format("2006-01-02")
+
+	switch format {
+	case exportJSONL:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.jsonl"`)
+
+		err := exporter.WriteJSONL(w, exportEntries(&server.Storage, server.Location, false), r.URL.Query().Get("embeddings") == "1")
+		if err != nil {
+			// The response has already started, so all that's left is to
+			// cut it short.
+			log.Println(err)
+		}
+
+	case exportMarkdown, exportHTML:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`-`+format+`.zip"`)
+
+		archive := zip.NewWriter(w)
+
+		err := exportArchive(archive, exportEntries(&server.Storage, server.Location, false), format, server.Location)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		err = archive.Close()
+		if err != nil {
+			log.Println(err)
+		}
+
+	default:
+		writeJSONError(w, http.StatusBadRequest, "format must be jsonl, markdown or html")
+	}
+}
@@ -0,0 +1,59 @@
+package exporter
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// An Archive receives the files of a multi-file export one after another.
+// *zip.Writer is one; DirArchive writes to a folder instead.
+type Archive interface {
+	Create(name string) (io.Writer, error)
+}
+
+// DirArchive writes each file under a root folder. Like a zip.Writer, a file
+// is finished when the next one is created or the archive is closed.
+type DirArchive struct {
+	root    string
+	current *os.File
+}
+
+func NewDirArchive(root string) *DirArchive {
+	return &DirArchive{root: root}
+}
+
+func (a *DirArchive) Create(name string) (io.Writer, error) {
+	err := a.closeCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(a.root, filepath.FromSlash(name))
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	a.current, err = os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.current, nil
+}
+
+func (a *DirArchive) Close() error {
+	return a.closeCurrent()
+}
+
+func (a *DirArchive) closeCurrent() error {
+	if a.current == nil {
+		return nil
+	}
+
+	err := a.current.Close()
+	a.current = nil
+	return err
+}
@@ -0,0 +1,134 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"spire/entry"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entries is a way to walk the journal one entry at a time, like
+// storage's ForEachEntry, so exports never hold the whole journal.
+type Entries func(fn func(entry.Entry) error) error
+
+type jsonLink struct {
+	Label string `json:"label"`
+	// Omitted for links that don't match any entry.
+	TargetID int64 `json:"target_id,omitempty"`
+}
+
+// Attachments are described rather than included; the hash names the blob
+// that holds the file.
+type jsonAttachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Hash        string `json:"hash"`
+}
+
+type jsonEntry struct {
+	ID          int64            `json:"id"`
+	Time        time.Time        `json:"time"`
+	Content     string           `json:"content"`
+	Tags        []string         `json:"tags"`
+	Links       []jsonLink       `json:"links"`
+	Attachments []jsonAttachment `json:"attachments"`
+	NotebookID  int64            `json:"notebook_id"`
+	Embedding   entry.Vector     `json:"embedding,omitempty"`
+	Private     bool             `json:"private,omitempty"`
+}
+
+// WriteJSONL writes one JSON object per line for each entry. Embeddings are
+// large, so they're only included when asked for.
+func WriteJSONL(w io.Writer, entries Entries, includeEmbeddings bool) error {
+	encoder := json.NewEncoder(w)
+
+	return entries(func(e entry.Entry) error {
+		line := jsonEntry{
+			ID:          e.ID,
+			Time:        e.Time,
+			Content:     e.Content,
+			Tags:        e.Tags,
+			Links:       make([]jsonLink, len(e.Links)),
+			Attachments: make([]jsonAttachment, len(e.Attachments)),
+			NotebookID:  e.NotebookID,
+			Private:     e.Private,
+		}
+
+		if line.Tags == nil {
+			line.Tags = []string{}
+		}
+
+		for i, link := range e.Links {
+			line.Links[i] = jsonLink{Label: link.Label, TargetID: link.TargetID}
+		}
+
+		for i, a := range e.Attachments {
+			line.Attachments[i] = jsonAttachment{Name: a.Name, ContentType: a.ContentType, Size: a.Size, Hash: a.Hash}
+		}
+
+		if includeEmbeddings {
+			line.Embedding = e.Embedding
+		}
+
+		return encoder.Encode(line)
+	})
+}
+
+type frontMatter struct {
+	ID      int64    `yaml:"id"`
+	Date    string   `yaml:"date"`
+	Tags    []string `yaml:"tags,omitempty"`
+	Private bool     `yaml:"private,omitempty"`
+}
+
+// MarkdownFileName names an entry's file so that a folder of them sorts by
+// date.
+func MarkdownFileName(e entry.Entry) string {
+	return fmt.Sprintf("%s-%d.md", e.Time.Format("2006-01-02"), e.ID)
+}
+
+// Markdown is an entry as a markdown file with YAML front matter, in the form
+// `spire import markdown` reads back.
+func Markdown(e entry.Entry) ([]byte, error) {
+	header, err := yaml.Marshal(frontMatter{
+		ID:      e.ID,
+		Date:    e.Time.Format(time.RFC3339),
+		Tags:    e.Tags,
+		Private: e.Private,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(header)
+	b.WriteString("---\n\n")
+	b.WriteString(e.Content)
+	b.WriteString("\n")
+
+	return b.Bytes(), nil
+}
+
+// WriteMarkdown writes each entry to its own file in the archive.
+func WriteMarkdown(archive Archive, entries Entries) error {
+	return entries(func(e entry.Entry) error {
+		data, err := Markdown(e)
+		if err != nil {
+			return err
+		}
+
+		w, err := archive.Create(MarkdownFileName(e))
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+		return err
+	})
+}
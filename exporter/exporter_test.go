@@ -0,0 +1,175 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"spire/entry"
+	"spire/importer"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEntries = []entry.Entry{
+	{
+		ID:        1,
+		Time:      time.Date(2024, time.January, 5, 9, 0, 0, 0, time.UTC),
+		Content:   "# Garden\n\nPlanted tomatoes.",
+		Tags:      []string{"garden"},
+		Embedding: entry.Vector{0.5, 0.25},
+		Attachments: []entry.Attachment{
+			{ID: 7, Name: "tomatoes.jpg", ContentType: "image/jpeg", Size: 2048, Hash: "abc123"},
+		},
+		NotebookID: 3,
+	},
+	{
+		ID:      2,
+		Time:    time.Date(2024, time.February, 1, 18, 30, 0, 0, time.UTC),
+		Content: "See [[Garden]] and [[Nowhere]].",
+		Links:   []entry.Link{{Label: "Garden", TargetID: 1}, {Label: "Nowhere"}},
+	},
+}
+
+func each(fn func(entry.Entry) error) error {
+	for _, e := range testEntries {
+		err := fn(e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestWriteJSONL(t *testing.T) {
+	for _, includeEmbeddings := range []bool{false, true} {
+		var b bytes.Buffer
+
+		err := WriteJSONL(&b, each, includeEmbeddings)
+		if err != nil {
+			t.Fatalf("error writing jsonl: %v\n", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected a line per entry, got %d", len(lines))
+		}
+
+		var first, second map[string]any
+		json.Unmarshal([]byte(lines[0]), &first)
+		json.Unmarshal([]byte(lines[1]), &second)
+
+		_, hasEmbedding := first["embedding"]
+		if hasEmbedding != includeEmbeddings {
+			t.Errorf("expected an embedding only when asked for, got %s", lines[0])
+		}
+
+		if first["content"] != testEntries[0].Content || first["time"] != "2024-01-05T09:00:00Z" {
+			t.Errorf("unexpected first line %s", lines[0])
+		}
+
+		if first["notebook_id"] != 3.0 ||
+			!strings.Contains(lines[0], `"attachments":[{"name":"tomatoes.jpg","content_type":"image/jpeg","size":2048,"hash":"abc123"}]`) {
+			t.Errorf("expected the notebook and attachments, got %s", lines[0])
+		}
+
+		if !strings.Contains(lines[1], `"tags":[]`) ||
+			!strings.Contains(lines[1], `"attachments":[]`) ||
+			!strings.Contains(lines[1], `{"label":"Garden","target_id":1}`) ||
+			!strings.Contains(lines[1], `{"label":"Nowhere"}`) {
+			t.Errorf("unexpected second line %s", lines[1])
+		}
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	e := testEntries[0]
+	e.Private = true
+
+	data, err := Markdown(e)
+	if err != nil {
+		t.Fatalf("error writing markdown: %v\n", err)
+	}
+
+	note, err := importer.ParseMarkdown(MarkdownFileName(e), data, time.Now(), time.UTC)
+	if err != nil {
+		t.Fatalf("error parsing markdown: %v\n", err)
+	}
+
+	if !note.Time.Equal(e.Time) || note.Content != e.Content || !slices.Equal(note.Tags, e.Tags) || !note.Private {
+		t.Errorf("expected the entry back, got %+v", note)
+	}
+}
+
+func TestWriteMarkdownToDir(t *testing.T) {
+	dir := t.TempDir()
+	archive := NewDirArchive(dir)
+
+	err := WriteMarkdown(archive, each)
+	if err != nil {
+		t.Fatalf("error writing markdown: %v\n", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		t.Fatalf("error closing archive: %v\n", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "2024-02-01-2.md"))
+	if err != nil {
+		t.Fatalf("error reading exported file: %v\n", err)
+	}
+
+	if !strings.HasSuffix(string(data), testEntries[1].Content+"\n") {
+		t.Errorf("expected the entry's content, got %q", data)
+	}
+}
+
+func TestWriteSite(t *testing.T) {
+	pages := template.Must(template.New("").Parse(`
+		{{define "site-style"}}body {}{{end}}
+		{{define "site-entry"}}{{.Content}}{{end}}
+		{{define "site-index"}}{{range .Months}}[{{.Month.Format "2006-01"}}{{range .Entries}} {{.ID}}:{{.Title}}{{end}}]{{end}}{{end}}
+	`))
+
+	var b bytes.Buffer
+	archive := zip.NewWriter(&b)
+
+	err := WriteSite(archive, each, pages, time.UTC)
+	if err != nil {
+		t.Fatalf("error writing site: %v\n", err)
+	}
+	archive.Close()
+
+	reader, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("error reading zip: %v\n", err)
+	}
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		f, _ := file.Open()
+		data, _ := io.ReadAll(f)
+		f.Close()
+		files[file.Name] = string(data)
+	}
+
+	if files["style.css"] != "body {}" {
+		t.Errorf("expected the stylesheet alongside the pages, got %q", files["style.css"])
+	}
+
+	if files["entries/1.html"] != testEntries[0].Content {
+		t.Errorf("expected a page per entry, got %v", files)
+	}
+
+	expected := "[2024-02 2:See [[Garden]] and [[Nowhere]].][2024-01 1:Garden]"
+	if files["index.html"] != expected {
+		t.Errorf("expected index %q, got %q", expected, files["index.html"])
+	}
+}
@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"html/template"
+	"spire/entry"
+	"strconv"
+	"time"
+)
+
+// The static site has an index at the root and a page per entry under
+// entries/, so links between entries are relative to that folder.
+
+// SiteEntryURL is where an entry's page is, relative to another entry's page.
+func SiteEntryURL(id int64) string {
+	return strconv.FormatInt(id, 10) + ".html"
+}
+
+type SiteIndexEntry struct {
+	ID    int64
+	Time  time.Time
+	Title string
+}
+
+type SiteIndexMonth struct {
+	Month   time.Time
+	Entries []SiteIndexEntry
+}
+
+type SiteIndex struct {
+	Generated time.Time
+	// Newest month first.
+	Months []SiteIndexMonth
+}
+
+// WriteSite renders each entry with the "site-entry" template and then an
+// index of all of them with "site-index", next to a stylesheet from
+// "site-style" so the site doesn't need anything from the network. Only the
+// index's titles and dates are kept in memory. loc is only used for the
+// index's generation time; entries are shown in whatever zone they come in.
+func WriteSite(archive Archive, entries Entries, pages *template.Template, loc *time.Location) error {
+	w, err := archive.Create("style.css")
+	if err != nil {
+		return err
+	}
+
+	err = pages.ExecuteTemplate(w, "site-style", nil)
+	if err != nil {
+		return err
+	}
+
+	var index []SiteIndexEntry
+
+	err = entries(func(e entry.Entry) error {
+		w, err := archive.Create("entries/" + SiteEntryURL(e.ID))
+		if err != nil {
+			return err
+		}
+
+		err = pages.ExecuteTemplate(w, "site-entry", e)
+		if err != nil {
+			return err
+		}
+
+		index = append(index, SiteIndexEntry{ID: e.ID, Time: e.Time, Title: entry.Title(e.Content)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w, err = archive.Create("index.html")
+	if err != nil {
+		return err
+	}
+
+	return pages.ExecuteTemplate(w, "site-index", SiteIndex{
+		Generated: time.Now().In(loc),
+		Months:    groupByMonth(index),
+	})
+}
+
+// groupByMonth groups entries given oldest first into months, newest first.
+func groupByMonth(entries []SiteIndexEntry) []SiteIndexMonth {
+	var months []SiteIndexMonth
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		month := time.Date(e.Time.Year(), e.Time.Month(), 1, 0, 0, 0, 0, e.Time.Location())
+
+		if len(months) == 0 || !months[len(months)-1].Month.Equal(month) {
+			months = append(months, SiteIndexMonth{Month: month})
+		}
+
+		last := &months[len(months)-1]
+		last.Entries = append(last.Entries, e)
+	}
+
+	return months
+}
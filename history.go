@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"spire/diff"
+	"spire/entry"
+	"spire/storage"
+	"strconv"
+	"time"
+)
+
+// An entryVersion is the entry's content as it was at some point, numbered
+// from 1 for the first version.
+type entryVersion struct {
+	Number int
+	// Zero for the current version.
+	RevisionID int64
+	Content    string
+	SavedAt    time.Time
+}
+
+// entryVersions lines up an entry's revisions and its current content as
+// versions, oldest first. Each version was saved when the one before it was
+// replaced.
+func entryVersions(current entry.Entry, revisions []storage.Revision) []entryVersion {
+	versions := make([]entryVersion, 0, len(revisions)+1)
+	savedAt := current.Time
+
+	for i, revision := range revisions {
+		versions = append(versions, entryVersion{
+			Number:     i + 1,
+			RevisionID: revision.ID,
+			Content:    revision.Content,
+			SavedAt:    savedAt,
+		})
+
+		savedAt = revision.ReplacedAt
+	}
+
+	return append(versions, entryVersion{
+		Number:  len(revisions) + 1,
+		Content: current.Content,
+		SavedAt: savedAt,
+	})
+}
+
+type historyPage struct {
+	CSRFToken string
+	Entry     entry.Entry
+	// Newest first.
+	Versions []entryVersion
+	From     entryVersion
+	To       entryVersion
+	Diff     template.HTML
+}
+
+// historyHandler lists an entry's versions and shows a word-level diff
+// between two of them, the latest change by default.
+func (server *Server) historyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if server.hidden(r, e) {
+		http.Error(w, "reveal the entry first", http.StatusForbidden)
+		return
+	}
+
+	revisions, err := server.Storage.GetRevisions(id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	versions := entryVersions(e, revisions)
+
+	to, err := versionFromQuery(r, "to", len(versions), len(versions))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, err := versionFromQuery(r, "from", len(versions), max(to-1, 1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page := historyPage{
+		Entry: e,
+		From:  versions[from-1],
+		To:    versions[to-1],
+	}
+
+	page.Diff = diff.HTML(diff.Words(page.From.Content, page.To.Content))
+
+	page.Versions = slices.Clone(versions)
+	slices.Reverse(page.Versions)
+
+	page.CSRFToken, err = server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "history.html", page)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// versionFromQuery reads a version number from the query, falling back to
+// the given default.
+func versionFromQuery(r *http.Request, key string, count int, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 || number > count {
+		return 0, errors.New("invalid version " + value)
+	}
+
+	return number, nil
+}
+
+// restoreRevisionHandler makes an earlier version the entry's content again.
+// The version being replaced becomes a revision itself, so nothing is lost.
+func (server *Server) restoreRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisionID, err := strconv.ParseInt(r.PathValue("revision"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid revision id", http.StatusBadRequest)
+		return
+	}
+
+	current, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if server.hidden(r, current) {
+		http.Error(w, "reveal the entry first", http.StatusForbidden)
+		return
+	}
+
+	revision, err := server.Storage.GetRevision(id, revisionID)
+	if errors.Is(err, storage.ErrRevisionNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	embedding, err := server.VoyageClient.GetEmbedding(revision.Content)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Hashtags only in the current content go with it. Tags added by hand
+	// stay.
+	hashtags := entry.ExtractTags(current.Content)
+	var tags []string
+	for _, tag := range current.Tags {
+		if !slices.Contains(hashtags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	err = server.Storage.UpdateEntry(entry.Entry{
+		ID:        id,
+		Content:   revision.Content,
+		Embedding: embedding,
+		Tags:      tags,
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.TopicsJob.Trigger()
+	server.MapJob.Trigger()
+
+	http.Redirect(w, r, "/entries/"+strconv.FormatInt(id, 10)+"/history", http.StatusSeeOther)
+}
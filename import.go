@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"spire/entry"
+	"spire/importer"
+	"spire/storage"
+	"strings"
+	"time"
+)
+
+// How many notes are queued per transaction, and so how often progress is
+// reported.
+const importBatchSize = 500
+
+// importNotes queues the notes that aren't already in the journal or the
+// queue, oldest first, for the embedding job to embed and save. Imports
+// don't wait on the embedding API, and an interrupted import keeps the
+// batches it finished, so running it again picks up the rest.
+func importNotes(store *storage.SQLiteStorage, notes []importer.Note, report *importer.Report, progress func(done, total int)) error {
+	existing, err := store.GetEntryTexts()
+	if err != nil {
+		return err
+	}
+
+	pending, err := store.GetPendingEntries(0)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, e := range append(existing, pending...) {
+		known[importer.ContentHash(e.Content)] = true
+	}
+
+	fresh := importer.Dedupe(notes, known, report)
+
+	// Oldest first, so IDs follow time and [[links]] to earlier notes resolve
+	// as they're saved.
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].Time.Before(fresh[j].Time)
+	})
+
+	if report.DryRun {
+		report.Imported = len(fresh)
+		return nil
+	}
+
+	for start := 0; start < len(fresh); start += importBatchSize {
+		batch := fresh[start:min(start+importBatchSize, len(fresh))]
+
+		entries := make([]entry.Entry, len(batch))
+		for i, note := range batch {
+			entries[i] = entry.Entry{Time: note.Time, Content: note.Content, Tags: note.Tags, Private: note.Private}
+		}
+
+		err = store.QueueEntries(entries)
+		if err != nil {
+			return err
+		}
+
+		report.Imported += len(batch)
+		if progress != nil {
+			progress(report.Imported, len(fresh))
+		}
+	}
+
+	return nil
+}
+
+// Uploads larger than this are refused before any of them is read.
+const maxImportUploadSize = 64 << 20
+
+type importReport struct {
+	Report importer.Report
+	Err    error
+}
+
+// importMarkdownHandler imports uploaded .md files. Browsers don't send
+// modification times with uploads, so the form sends them alongside as
+// "modified", a JSON list in the same order as the files.
+func (server *Server) importMarkdownHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportUploadSize)
+
+	err := r.ParseMultipartForm(maxImportUploadSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var modified []int64
+	if value := r.PostForm.Get("modified"); value != "" {
+		err = json.Unmarshal([]byte(value), &modified)
+		if err != nil {
+			http.Error(w, "invalid modification times: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var result importReport
+	var notes []importer.Note
+
+	for i, header := range r.MultipartForm.File["files"] {
+		if !strings.EqualFold(filepath.Ext(header.Filename), ".md") {
+			continue
+		}
+
+		result.Report.Found++
+
+		modTime := time.Now()
+		if i < len(modified) {
+			modTime = time.UnixMilli(modified[i])
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			result.Report.Fail(header.Filename, err)
+			continue
+		}
+
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			result.Report.Fail(header.Filename, err)
+			continue
+		}
+
+		note, err := importer.ParseMarkdown(header.Filename, data, modTime, server.Location)
+		if err != nil {
+			result.Report.Fail(header.Filename, err)
+			continue
+		}
+
+		notes = append(notes, note)
+	}
+
+	result.Err = importNotes(&server.Storage, notes, &result.Report, nil)
+	if result.Err != nil {
+		log.Println(result.Err)
+	}
+
+	if result.Report.Imported > 0 {
+		server.EmbedJob.Trigger()
+	}
+
+	err = templates.ExecuteTemplate(w, "import-report", result)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The parts of Day One's JSON export that Spire uses. Each journal in the
+// export is a separate .json file with a list of entries.
+type dayOneExport struct {
+	Entries []dayOneEntry `json:"entries"`
+}
+
+type dayOneEntry struct {
+	UUID         string          `json:"uuid"`
+	CreationDate string          `json:"creationDate"`
+	TimeZone     string          `json:"timeZone"`
+	Text         string          `json:"text"`
+	Tags         []string        `json:"tags"`
+	Starred      bool            `json:"starred"`
+	Location     json.RawMessage `json:"location"`
+	Photos       json.RawMessage `json:"photos"`
+}
+
+// Day One marks where photos go with links like ![](dayone-moment://UUID).
+var dayOneMomentPattern = regexp.MustCompile(`!\[[^\]]*\]\(dayone-moment:/+[^)]*\)\n?`)
+
+// StarredTag marks entries that were starred in Day One, since Spire has no
+// separate flag for it.
+const StarredTag = "starred"
+
+// ReadDayOne reads every journal in a Day One JSON export. Entries keep the
+// time zone they were written in; ones without a usable zone are read in loc.
+// Locations and photos aren't stored by Spire, so they're counted in the
+// report as dropped.
+func ReadDayOne(zipPath string, loc *time.Location, report *Report) ([]Note, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var notes []Note
+
+	for _, file := range archive.File {
+		// Skip the resource forks macOS adds when zipping.
+		if !strings.EqualFold(path.Ext(file.Name), ".json") || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		var export dayOneExport
+		err = json.NewDecoder(reader).Decode(&export)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+
+		for _, e := range export.Entries {
+			report.Found++
+
+			source := file.Name + "#" + e.UUID
+			note, err := parseDayOneEntry(source, e, loc, report)
+			if err != nil {
+				report.Fail(source, err)
+				continue
+			}
+
+			notes = append(notes, note)
+		}
+	}
+
+	return notes, nil
+}
+
+func parseDayOneEntry(source string, e dayOneEntry, loc *time.Location, report *Report) (Note, error) {
+	created, err := time.Parse(time.RFC3339, e.CreationDate)
+	if err != nil {
+		return Note{}, fmt.Errorf("invalid creation date: %w", err)
+	}
+
+	zone := loc
+	if e.TimeZone != "" {
+		if entryZone, err := time.LoadLocation(e.TimeZone); err == nil {
+			zone = entryZone
+		}
+	}
+
+	note := Note{
+		Source:  source,
+		Time:    created.In(zone),
+		Content: strings.TrimSpace(dayOneMomentPattern.ReplaceAllString(e.Text, "")),
+		Tags:    e.Tags,
+	}
+
+	if e.Starred {
+		note.Tags = append(note.Tags, StarredTag)
+	}
+
+	if hasValue(e.Location) {
+		report.Drop("locations")
+	}
+
+	if hasValue(e.Photos) {
+		report.Drop("photos")
+	}
+
+	return note, nil
+}
+
+func hasValue(raw json.RawMessage) bool {
+	value := strings.TrimSpace(string(raw))
+	return value != "" && value != "null" && value != "[]" && value != "{}"
+}
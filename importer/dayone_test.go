@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const dayOneJournal = `{
+  "metadata": {"version": "1.0"},
+  "entries": [
+    {
+      "uuid": "A1",
+      "creationDate": "2021-07-04T23:30:00Z",
+      "timeZone": "America/Los_Angeles",
+      "text": "Fireworks by the bay.\n![](dayone-moment://ABC123)\nLate night.",
+      "tags": ["Summer"],
+      "starred": true,
+      "location": {"placeName": "Embarcadero", "latitude": 37.79, "longitude": -122.39},
+      "photos": [{"identifier": "ABC123"}]
+    },
+    {
+      "uuid": "B2",
+      "creationDate": "2021-07-05T08:00:00Z",
+      "text": "Quiet morning."
+    },
+    {
+      "uuid": "C3",
+      "creationDate": "yesterday",
+      "text": "Broken date."
+    }
+  ]
+}`
+
+func TestReadDayOne(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("error creating zip: %v\n", err)
+	}
+
+	archive := zip.NewWriter(file)
+	for name, content := range map[string]string{
+		"Journal.json":            dayOneJournal,
+		"__MACOSX/._Journal.json": "not json",
+		"photos/ABC123.jpeg":      "not a photo",
+	} {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("error adding to zip: %v\n", err)
+		}
+
+		writer.Write([]byte(content))
+	}
+	archive.Close()
+	file.Close()
+
+	var report Report
+	notes, err := ReadDayOne(zipPath, time.UTC, &report)
+	if err != nil {
+		t.Fatalf("error reading export: %v\n", err)
+	}
+
+	if report.Found != 3 || len(notes) != 2 || len(report.Failed) != 1 {
+		t.Fatalf("expected 3 entries found, 2 read and 1 failure, got %d, %d and %v", report.Found, len(notes), report.Failed)
+	}
+
+	fireworks := notes[0]
+
+	// Written in the evening of the 4th in California, which was already the
+	// 5th in UTC.
+	if fireworks.Time.Day() != 4 || fireworks.Time.Hour() != 16 {
+		t.Errorf("expected the entry's own time zone to be kept, got %v", fireworks.Time)
+	}
+
+	if fireworks.Content != "Fireworks by the bay.\nLate night." {
+		t.Errorf("expected the photo placeholder to be removed, got %q", fireworks.Content)
+	}
+
+	if !slices.Equal(fireworks.Tags, []string{"Summer", StarredTag}) {
+		t.Errorf("expected the tags plus starred, got %v", fireworks.Tags)
+	}
+
+	if report.Dropped["locations"] != 1 || report.Dropped["photos"] != 1 {
+		t.Errorf("expected one location and one set of photos dropped, got %v", report.Dropped)
+	}
+
+	if notes[1].Time.Location() != time.UTC {
+		t.Errorf("expected an entry without a zone to use the given one, got %v", notes[1].Time.Location())
+	}
+}
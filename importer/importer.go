@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A Note is one entry to be imported, from whatever format it came in.
+type Note struct {
+	// Where the note came from, like a file path, for reporting.
+	Source  string
+	Time    time.Time
+	Content string
+	Tags    []string
+	Private bool
+}
+
+// ContentHash identifies a note by its content, ignoring surrounding
+// whitespace, so importing the same notes twice doesn't duplicate them.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// A Failure is a note that couldn't be read or saved.
+type Failure struct {
+	Source string
+	Err    error
+}
+
+// Report summarizes an import run.
+type Report struct {
+	// Nothing was saved; Imported is what would have been.
+	DryRun     bool
+	Found      int
+	Imported   int
+	Duplicates int
+	Empty      int
+	// How many notes had each kind of data Spire doesn't store, like
+	// "locations".
+	Dropped map[string]int
+	Failed  []Failure
+}
+
+func (r *Report) Fail(source string, err error) {
+	r.Failed = append(r.Failed, Failure{Source: source, Err: err})
+}
+
+func (r *Report) Drop(kind string) {
+	if r.Dropped == nil {
+		r.Dropped = map[string]int{}
+	}
+
+	r.Dropped[kind]++
+}
+
+func (r Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "found %d notes\n", r.Found)
+	if r.DryRun {
+		fmt.Fprintf(w, "would import %d\n", r.Imported)
+	} else {
+		fmt.Fprintf(w, "imported %d\n", r.Imported)
+	}
+	fmt.Fprintf(w, "skipped %d already in the journal\n", r.Duplicates)
+	if r.Empty > 0 {
+		fmt.Fprintf(w, "skipped %d empty\n", r.Empty)
+	}
+
+	kinds := make([]string, 0, len(r.Dropped))
+	for kind := range r.Dropped {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "left out %s from %d notes, which Spire doesn't store\n", kind, r.Dropped[kind])
+	}
+
+	if len(r.Failed) > 0 {
+		fmt.Fprintf(w, "failed %d:\n", len(r.Failed))
+		for _, failure := range r.Failed {
+			fmt.Fprintf(w, "  %s: %v\n", failure.Source, failure.Err)
+		}
+	}
+}
+
+// Dedupe drops empty notes and notes whose content hash is already known,
+// including repeats within notes itself. Hashes of the notes kept are added
+// to known.
+func Dedupe(notes []Note, known map[string]bool, report *Report) []Note {
+	var fresh []Note
+
+	for _, note := range notes {
+		if strings.TrimSpace(note.Content) == "" {
+			report.Empty++
+			continue
+		}
+
+		hash := ContentHash(note.Content)
+		if known[hash] {
+			report.Duplicates++
+			continue
+		}
+
+		known[hash] = true
+		fresh = append(fresh, note)
+	}
+
+	return fresh
+}
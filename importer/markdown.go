@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"spire/entry"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The front matter fields Spire understands. Anything else is ignored.
+type frontMatter struct {
+	Title string `yaml:"title"`
+	// Kept as nodes so dates are read as written, rather than as the UTC
+	// times YAML would make of them.
+	Date    yaml.Node `yaml:"date"`
+	Created yaml.Node `yaml:"created"`
+	// Either a list or a comma or space separated string.
+	Tags any `yaml:"tags"`
+	// Written by Spire's own markdown export.
+	Private bool `yaml:"private"`
+}
+
+// Layouts tried for dates written as strings, most specific first.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseMarkdown turns a markdown file into a note. The date comes from the
+// front matter's date or created field, falling back to modTime. Dates
+// without a zone are read in loc. A front matter title becomes the entry's
+// heading so [[links]] to it resolve.
+func ParseMarkdown(source string, data []byte, modTime time.Time, loc *time.Location) (Note, error) {
+	note := Note{Source: source, Time: modTime.In(loc)}
+
+	header, body, err := splitFrontMatter(data)
+	if err != nil {
+		return Note{}, err
+	}
+
+	var matter frontMatter
+	err = yaml.Unmarshal(header, &matter)
+	if err != nil {
+		return Note{}, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	for _, node := range []yaml.Node{matter.Date, matter.Created} {
+		if node.Value == "" {
+			continue
+		}
+
+		note.Time, err = parseDate(node.Value, loc)
+		if err != nil {
+			return Note{}, err
+		}
+		break
+	}
+
+	note.Tags, err = parseTags(matter.Tags)
+	if err != nil {
+		return Note{}, err
+	}
+
+	note.Private = matter.Private
+
+	note.Content = strings.TrimSpace(string(body))
+	if matter.Title != "" && entry.Title(note.Content) != matter.Title {
+		note.Content = strings.TrimSpace("# " + matter.Title + "\n\n" + note.Content)
+	}
+
+	return note, nil
+}
+
+// splitFrontMatter separates a leading YAML block fenced by --- lines from
+// the rest of the file. Files without one have an empty header.
+func splitFrontMatter(data []byte) ([]byte, []byte, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	rest, ok := bytes.CutPrefix(normalized, []byte("---\n"))
+	if !ok {
+		return nil, data, nil
+	}
+
+	// The closing fence may be the last line of the file.
+	rest = append(rest, '\n')
+	for _, fence := range []string{"---\n", "...\n"} {
+		if bytes.HasPrefix(rest, []byte(fence)) {
+			return nil, rest[len(fence):], nil
+		}
+
+		header, body, found := bytes.Cut(rest, []byte("\n"+fence))
+		if found {
+			return header, body, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("front matter is never closed")
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(value), loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+func parseTags(value any) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return entry.ParseTagList(value), nil
+
+	case []any:
+		tags := make([]string, 0, len(value))
+		for _, tag := range value {
+			tags = append(tags, fmt.Sprint(tag))
+		}
+		return entry.MergeTags(tags), nil
+	}
+
+	return nil, fmt.Errorf("unrecognized tags %v", value)
+}
+
+// ReadMarkdownDir parses every .md file under dir. Hidden files and folders,
+// like Obsidian's .obsidian and .trash, are skipped. Files that can't be
+// parsed are added to the report rather than stopping the import.
+func ReadMarkdownDir(dir string, loc *time.Location, report *Report) ([]Note, error) {
+	var notes []Note
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		report.Found++
+
+		info, err := d.Info()
+		if err != nil {
+			report.Fail(path, err)
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report.Fail(path, err)
+			return nil
+		}
+
+		note, err := ParseMarkdown(path, data, info.ModTime(), loc)
+		if err != nil {
+			report.Fail(path, err)
+			return nil
+		}
+
+		notes = append(notes, note)
+		return nil
+	})
+
+	return notes, err
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("error loading location: %v\n", err)
+	}
+
+	modTime := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name     string
+		data     string
+		expected Note
+	}{
+		{
+			name: "date only",
+			data: "---\ndate: 2023-05-06\ntags: [Work, \"#ideas\"]\n---\nShipped it.\n",
+			expected: Note{
+				Time:    time.Date(2023, time.May, 6, 0, 0, 0, 0, loc),
+				Content: "Shipped it.",
+				Tags:    []string{"ideas", "work"},
+			},
+		},
+		{
+			name: "date and time with a zone",
+			data: "---\ndate: 2023-05-06T08:30:00Z\ntags: work, travel\n---\n\nOn the train.",
+			expected: Note{
+				Time:    time.Date(2023, time.May, 6, 8, 30, 0, 0, time.UTC),
+				Content: "On the train.",
+				Tags:    []string{"travel", "work"},
+			},
+		},
+		{
+			name: "created and a title",
+			data: "---\r\ntitle: Morning pages\r\ncreated: \"2023-05-06 07:15\"\r\n---\r\nCoffee first.\r\n",
+			expected: Note{
+				Time:    time.Date(2023, time.May, 6, 7, 15, 0, 0, loc),
+				Content: "# Morning pages\n\nCoffee first.",
+			},
+		},
+		{
+			name: "private",
+			data: "---\ndate: 2023-05-06\nprivate: true\n---\nNobody else.\n",
+			expected: Note{
+				Time:    time.Date(2023, time.May, 6, 0, 0, 0, 0, loc),
+				Content: "Nobody else.",
+				Private: true,
+			},
+		},
+		{
+			name:     "no front matter",
+			data:     "Just a note #later",
+			expected: Note{Time: modTime, Content: "Just a note #later"},
+		},
+	} {
+		note, err := ParseMarkdown("note.md", []byte(test.data), modTime, loc)
+		if err != nil {
+			t.Errorf("%s: error parsing: %v", test.name, err)
+			continue
+		}
+
+		if !note.Time.Equal(test.expected.Time) {
+			t.Errorf("%s: expected time %v, got %v", test.name, test.expected.Time, note.Time)
+		}
+
+		if note.Content != test.expected.Content {
+			t.Errorf("%s: expected content %q, got %q", test.name, test.expected.Content, note.Content)
+		}
+
+		if !slices.Equal(note.Tags, test.expected.Tags) {
+			t.Errorf("%s: expected tags %v, got %v", test.name, test.expected.Tags, note.Tags)
+		}
+
+		if note.Private != test.expected.Private {
+			t.Errorf("%s: expected private to be %v", test.name, test.expected.Private)
+		}
+	}
+
+	_, err = ParseMarkdown("broken.md", []byte("---\ndate: 2023-05-06\nno closing fence"), modTime, loc)
+	if err == nil {
+		t.Errorf("expected an error for unclosed front matter")
+	}
+
+	_, err = ParseMarkdown("broken.md", []byte("---\ndate: someday\n---\n"), modTime, loc)
+	if err == nil {
+		t.Errorf("expected an error for an unrecognized date")
+	}
+}
+
+func TestReadMarkdownDir(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"2023-01-01.md":          "New year.",
+		"trips/lisbon.MD":        "---\ndate: 2023-06-01\n---\nTrams.",
+		".obsidian/workspace.md": "not a note",
+		"attachments/photo.png":  "not markdown",
+		"broken.md":              "---\ndate: nope\n---\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("error creating folder: %v\n", err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("error writing file: %v\n", err)
+		}
+	}
+
+	var report Report
+	notes, err := ReadMarkdownDir(dir, time.UTC, &report)
+	if err != nil {
+		t.Fatalf("error reading folder: %v\n", err)
+	}
+
+	if report.Found != 3 || len(notes) != 2 || len(report.Failed) != 1 {
+		t.Errorf("expected 3 notes found, 2 read and 1 failure, got %d, %d and %v", report.Found, len(notes), report.Failed)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	known := map[string]bool{ContentHash("already here"): true}
+
+	var report Report
+	fresh := Dedupe([]Note{
+		{Content: "  already here\n"},
+		{Content: "new"},
+		{Content: "new"},
+		{Content: " "},
+	}, known, &report)
+
+	if len(fresh) != 1 || fresh[0].Content != "new" {
+		t.Errorf("expected only the first new note, got %v", fresh)
+	}
+
+	if report.Duplicates != 2 || report.Empty != 1 {
+		t.Errorf("expected 2 duplicates and 1 empty, got %+v", report)
+	}
+}
@@ -1,39 +1,178 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"spire/background"
+	"spire/blobs"
 	"spire/entry"
+	"spire/markdown"
+	"spire/session"
 	"spire/storage"
+	"spire/token"
 	"spire/voyage"
 	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 )
 
-var templates = template.Must(template.ParseFiles(
+var templateFuncs = template.FuncMap{
+	"markdown":     markdown.Render,
+	"entryContent": entryContentRenderer(entryURL),
+	"entryTitle":   entryTitle,
+	"entryURL":     entryURL,
+	// Whether entries get buttons that call back into the app. They don't in
+	// exports.
+	"interactive": func() bool { return true },
+	"add":         func(a, b int) int { return a + b },
+	"sub":         func(a, b int) int { return a - b },
+	"fileSize":    fileSize,
+}
+
+func entryURL(id int64) string {
+	return "/entries/" + strconv.FormatInt(id, 10)
+}
+
+// Shown instead of a private entry's content and title until it's revealed.
+const privatePlaceholder = "Private entry"
+
+func entryTitle(e entry.Entry) string {
+	if e.Hidden() {
+		return privatePlaceholder
+	}
+
+	return entry.Title(e.Content)
+}
+
+// entryContentRenderer renders an entry's markdown with its resolved [[links]]
+// pointing at the linked entries' URLs.
+func entryContentRenderer(url func(id int64) string) func(e entry.Entry) (template.HTML, error) {
+	return func(e entry.Entry) (template.HTML, error) {
+		if e.Hidden() {
+			return template.HTML("<p><em>" + privatePlaceholder + "</em></p>"), nil
+		}
+
+		hrefs := map[string]string{}
+		for _, link := range e.Links {
+			if link.TargetID != 0 {
+				hrefs[link.Label] = url(link.TargetID)
+			}
+		}
+
+		return markdown.Render(entry.ReplaceLinks(e.Content, hrefs))
+	}
+}
+
+var templates = template.Must(template.New("").Funcs(templateFuncs).ParseFiles(
 	"templates/index.html",
+	"templates/settings.html",
+	"templates/entry_page.html",
+	"templates/on_this_day.html",
+	"templates/calendar.html",
+	"templates/stats.html",
+	"templates/topics.html",
+	"templates/map.html",
+	"templates/trends.html",
+	"templates/trash.html",
+	"templates/history.html",
+	"templates/share.html",
+	"templates/components/head.html",
+	"templates/components/nav.html",
 	"templates/components/entry.html",
 	"templates/components/entries.html",
+	"templates/components/token.html",
+	"templates/components/tags.html",
+	"templates/components/preview.html",
+	"templates/components/on_this_day.html",
+	"templates/components/duplicate.html",
+	"templates/components/import.html",
+	"templates/components/trash.html",
+	"templates/components/private.html",
+	"templates/components/notebooks.html",
+	"templates/components/shares.html",
 ))
 
 type Server struct {
 	Storage      storage.SQLiteStorage
 	VoyageClient voyage.VoyageClient
+	Sessions     *session.Manager
+	// Files attached to entries.
+	Blobs *blobs.Store
+	// What has to be entered to reveal private entries. They can't be
+	// revealed if it's empty.
+	Password string
+	// The journal's time zone, for anything that depends on calendar days.
+	Location *time.Location
+	// Entries at least this similar to a recent one need confirming before
+	// they're saved. Zero turns the check off.
+	DuplicateThreshold float64
+	// Embeds imported entries and moves them into the journal.
+	EmbedJob *background.Job
+	// How long deleted entries stay in the trash. Zero keeps them until
+	// they're purged by hand.
+	TrashRetention time.Duration
+	// Places new entries in topics, and reclusters when needed.
+	TopicsJob *background.Job
+	// Places new entries on the map, and refits the projection when needed.
+	MapJob *background.Job
+}
+
+type indexPage struct {
+	CSRFToken string
+	Entries   []entry.Entry
+	TagCloud  tagCloud
+	Notebooks notebookSwitcher
+}
+
+type tagCloud struct {
+	Tags []storage.TagCount
+	// Set when the cloud is sent along with another fragment, so htmx swaps
+	// it in place of the current one.
+	OOB bool
 }
 
 func (server *Server) baseHandler(w http.ResponseWriter, r *http.Request) {
-	entries, err := server.Storage.GetEntries()
+	notebooks, err := server.loadNotebookSwitcher(r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := server.Storage.GetNotebookEntries(notebooks.Current.ID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := server.Storage.GetTagCounts(server.unlocked(r))
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	err = templates.ExecuteTemplate(w, "index.html", entries)
+	err = templates.ExecuteTemplate(w, "index.html", indexPage{
+		CSRFToken: csrfToken,
+		Entries:   entries,
+		TagCloud:  tagCloud{Tags: tags},
+		Notebooks: notebooks,
+	})
 
 	if err != nil {
 		log.Println(err)
@@ -42,30 +181,156 @@ func (server *Server) baseHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (server *Server) newEntryHandler(w http.ResponseWriter, r *http.Request) {
-	r.ParseForm()
+	var uploads []upload
+
+	// The form is only multipart when files are attached.
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxEntryUploadSize)
+
+		err := r.ParseMultipartForm(maxAttachmentSize)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		uploads, err = checkUploads(r.MultipartForm.File["attachments"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		r.ParseForm()
+	}
 
 	content := r.PostForm.Get("entry")
+	tags := entry.ParseTagList(r.PostForm.Get("tags"))
+	private := r.PostForm.Get("private") != ""
+
+	notebook, err := server.currentNotebook(r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	embedding, err := server.VoyageClient.GetEmbedding(content)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	newEntry := entry.Entry{
-		Time:      time.Now(),
-		Content:   content,
-		Embedding: embedding,
+	// Once the user has seen the near-duplicate and chosen to save anyway,
+	// don't ask again. The warning can't carry files along to a second
+	// submission, and new files make it a new entry anyway, so entries with
+	// attachments aren't checked. Neither are private ones, since merging one
+	// into an entry that isn't private would give it away.
+	if r.PostForm.Get("confirmed") == "" && len(uploads) == 0 && !private {
+		existing, similarity, found, err := server.findDuplicate(embedding)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if found {
+			server.renderDuplicateWarning(w, newDuplicateWarning(content, tags, existing, similarity))
+			return
+		}
 	}
 
-	err = server.Storage.SaveEntry(newEntry)
+	attachments, err := server.storeUploads(uploads)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
+	newEntry, err := server.saveEntry(entry.Entry{
+		Content:     content,
+		Embedding:   embedding,
+		Tags:        tags,
+		Attachments: attachments,
+		Private:     private,
+		NotebookID:  notebook.ID,
+	})
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	err = templates.ExecuteTemplate(w, "entry.html", newEntry)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "duplicate-warning-cleared", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	server.renderTagCloudOOB(w, r)
+}
+
+// How many neighbors to show for "more like this".
+const similarEntriesLimit = 5
+
+type entryPage struct {
+	CSRFToken string
+	Entry     entry.Entry
+	Backlinks []entry.Entry
+	Similar   []entry.Entry
+}
+
+func (server *Server) entryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	backlinks, err := server.Storage.GetBacklinks(id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	similar, err := server.Storage.GetSimilarEntries(id, similarEntriesLimit)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "entry_page.html", entryPage{
+		CSRFToken: csrfToken,
+		Entry:     e,
+		Backlinks: backlinks,
+		Similar:   similar,
+	})
 
 	if err != nil {
 		log.Println(err)
@@ -73,36 +338,157 @@ func (server *Server) newEntryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (server *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+func (server *Server) similarEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	similar, err := server.Storage.GetSimilarEntries(id, similarEntriesLimit)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "entries.html", similar)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func entryIDFromPath(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid entry id")
+	}
+
+	return id, nil
+}
+
+func (server *Server) editTagsHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if server.hidden(r, e) {
+		http.Error(w, "reveal the entry before editing its tags", http.StatusForbidden)
+		return
+	}
+
 	r.ParseForm()
 
-	content := r.PostForm.Get("search")
+	err := server.Storage.SetEntryTags(e.ID, entry.ParseTagList(r.PostForm.Get("tags")))
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := server.Storage.GetEntry(e.ID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated.Revealed = !server.hidden(r, updated)
+	err = templates.ExecuteTemplate(w, "entry.html", updated)
 
-	var entries []entry.Entry
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderTagCloudOOB(w, r)
+}
+
+// renderTagCloudOOB appends the tag cloud to a response that changed tags.
+// The main fragment has already been written, so errors are only logged.
+func (server *Server) renderTagCloudOOB(w http.ResponseWriter, r *http.Request) {
+	tags, err := server.Storage.GetTagCounts(server.unlocked(r))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "tags.html", tagCloud{Tags: tags, OOB: true})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (server *Server) createEntry(content string, tags []string) (entry.Entry, error) {
+	embedding, err := server.VoyageClient.GetEmbedding(content)
+	if err != nil {
+		return entry.Entry{}, err
+	}
+
+	return server.saveEntry(entry.Entry{Content: content, Embedding: embedding, Tags: tags})
+}
+
+// saveEntry stores a new entry, written now, whose embedding has already been
+// computed.
+func (server *Server) saveEntry(newEntry entry.Entry) (entry.Entry, error) {
 	var err error
 
-	if content == "" {
-		entries, err = server.Storage.GetEntries()
-	} else if strings.HasPrefix(content, "vibe:") {
-		searchTerm := content[5:]
+	newEntry.Time = time.Now().In(server.Location)
 
-		if len(searchTerm) != 0 {
-			embedding, err := server.VoyageClient.GetEmbedding(searchTerm)
+	newEntry.ID, err = server.Storage.SaveEntry(newEntry)
+	if err != nil {
+		return entry.Entry{}, err
+	}
 
-			if err != nil {
-				log.Println(err)
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
+	server.TopicsJob.Trigger()
+	server.MapJob.Trigger()
 
-			entries, err = server.Storage.SearchEntriesEmbedding(embedding)
-		}
-	} else {
-		entries, err = server.Storage.SearchEntries(content)
+	// Re-read so the entry comes back with the tags extracted on save.
+	return server.Storage.GetEntry(newEntry.ID)
+}
+
+func (server *Server) previewHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	err := templates.ExecuteTemplate(w, "preview.html", r.PostForm.Get("entry"))
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
+}
+
+func (server *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
 
+	entries, err := server.search(r.PostForm.Get("search"), server.unlocked(r))
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.PostForm.Get("scope") != allNotebooksScope {
+		notebook, err := server.currentNotebook(r)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		entries = inNotebook(entries, notebook.ID)
 	}
 
 	err = templates.ExecuteTemplate(w, "entries.html", entries)
@@ -113,28 +499,308 @@ func (server *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// An empty query returns everything, "vibe:" searches by embedding, "tag:"
+// filters by tag, and anything else is a plain text search. Unless
+// includePrivate is set, private entries are left out of anything but the
+// empty query, so their placeholders can't be used to test what they say.
+func (server *Server) search(content string, includePrivate bool) ([]entry.Entry, error) {
+	if content == "" {
+		return server.Storage.GetEntries()
+	}
+
+	entries, err := server.match(content)
+	if err != nil || includePrivate {
+		return entries, err
+	}
+
+	var result []entry.Entry
+	for _, e := range entries {
+		if !e.Private {
+			result = append(result, e)
+		}
+	}
+
+	return result, nil
+}
+
+func (server *Server) match(content string) ([]entry.Entry, error) {
+	if searchTerm, ok := strings.CutPrefix(content, "vibe:"); ok {
+		if len(searchTerm) == 0 {
+			return nil, nil
+		}
+
+		embedding, err := server.VoyageClient.GetEmbedding(searchTerm)
+		if err != nil {
+			return nil, err
+		}
+
+		return server.Storage.SearchEntriesEmbedding(embedding)
+	}
+
+	if tag, ok := strings.CutPrefix(content, "tag:"); ok {
+		return server.Storage.GetEntriesByTag(tag)
+	}
+
+	return server.Storage.SearchEntries(content)
+}
+
+// journalLocation is the time zone set by SPIRE_TIMEZONE, or the system's.
+func journalLocation() (*time.Location, error) {
+	name := os.Getenv("SPIRE_TIMEZONE")
+	if name == "" {
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("error loading SPIRE_TIMEZONE: %w", err)
+	}
+
+	return location, nil
+}
+
+const (
+	databasePath = "main.db"
+	// How often a replica pulls changes made on other machines.
+	defaultSyncInterval = time.Minute
+)
+
+// openStorage opens main.db, as an embedded replica of SPIRE_PRIMARY_URL if
+// that's set.
+func openStorage() (*storage.SQLiteStorage, error) {
+	primaryURL := os.Getenv("SPIRE_PRIMARY_URL")
+	if primaryURL == "" {
+		return storage.NewSQLiteStorage(databasePath)
+	}
+
+	config := storage.ReplicaConfig{
+		PrimaryURL:   primaryURL,
+		AuthToken:    os.Getenv("SPIRE_AUTH_TOKEN"),
+		SyncInterval: defaultSyncInterval,
+	}
+
+	if value := os.Getenv("SPIRE_SYNC_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("SPIRE_SYNC_INTERVAL must be a duration like 30s, got %q", value)
+		}
+
+		config.SyncInterval = interval
+	}
+
+	return storage.NewReplicaStorage(databasePath, config)
+}
+
+// unlockStorage gives an encrypted journal its passphrase. Nothing can be
+// read from one without it, so it's an error for it to be missing.
+func unlockStorage(store *storage.SQLiteStorage) error {
+	encrypted, err := store.Encrypted()
+	if err != nil || !encrypted {
+		return err
+	}
+
+	passphrase := os.Getenv("SPIRE_PASSPHRASE")
+	if passphrase == "" {
+		return errors.New("main.db is encrypted; set SPIRE_PASSPHRASE to open it")
+	}
+
+	err = store.Unlock(passphrase)
+	if err != nil {
+		return fmt.Errorf("unlocking main.db: %w", err)
+	}
+
+	return nil
+}
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatal("error loading .env")
 	}
 
-	store, err := storage.NewSQLiteStorage("main.db")
+	// Restoring is for when main.db is broken, so it can't wait on opening it.
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		err = restoreCommand(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	store, err := openStorage()
 	if err != nil {
 		log.Fatalf("error initializing database: %v\n", err)
 	}
+	defer store.Close()
+
+	err = unlockStorage(store)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) > 1 {
+		err = runCommand(store, os.Args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	encrypted, err := store.Encrypted()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !encrypted && os.Getenv("SPIRE_PASSPHRASE") != "" {
+		log.Println("SPIRE_PASSPHRASE is set but main.db isn't encrypted; run spire encrypt to encrypt it")
+	}
+
+	secret := []byte(os.Getenv("SPIRE_SECRET"))
+	if len(secret) == 0 {
+		log.Println("SPIRE_SECRET is not set, sessions will not survive a restart")
+
+		secret, err = session.NewRandomSecret()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	location, err := journalLocation()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	duplicateThreshold := defaultDuplicateThreshold
+	if value := os.Getenv("SPIRE_DUPLICATE_THRESHOLD"); value != "" {
+		duplicateThreshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || duplicateThreshold < 0 || duplicateThreshold > 1 {
+			log.Fatalf("SPIRE_DUPLICATE_THRESHOLD must be a number from 0 to 1, got %q\n", value)
+		}
+	}
+
+	trashRetention := defaultTrashRetention
+	if value := os.Getenv("SPIRE_TRASH_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			log.Fatalf("SPIRE_TRASH_DAYS must be a whole number of days, got %q\n", value)
+		}
+
+		trashRetention = time.Duration(days) * 24 * time.Hour
+	}
+
+	blobStore, err := blobs.NewStore(blobDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schedule, err := backupScheduleFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// An encrypted journal already has a passphrase to ask for.
+	password := os.Getenv("SPIRE_PASSWORD")
+	if password == "" {
+		password = os.Getenv("SPIRE_PASSPHRASE")
+	}
 
 	server := Server{
-		*store,
-		voyage.NewClient(os.Getenv("VOYAGE_API_KEY")),
+		Storage:            *store,
+		VoyageClient:       voyage.NewClient(os.Getenv("VOYAGE_API_KEY")),
+		Sessions:           session.NewManager(secret),
+		Blobs:              blobStore,
+		Password:           password,
+		Location:           location,
+		DuplicateThreshold: duplicateThreshold,
+		TrashRetention:     trashRetention,
+	}
+
+	server.EmbedJob = background.NewJob("embeddings", server.embedPending)
+	server.EmbedJob.Start(embedInterval)
+	server.EmbedJob.Trigger()
+
+	server.TopicsJob = background.NewJob("topics", server.refreshTopics)
+	server.TopicsJob.Start(0)
+	server.TopicsJob.Trigger()
+
+	server.MapJob = background.NewJob("map", server.refreshMap)
+	server.MapJob.Start(0)
+	server.MapJob.Trigger()
+
+	background.NewJob("trash", server.purgeTrash).Start(trashPurgeInterval)
+
+	if schedule.Dir != "" {
+		background.NewJob("backup", scheduledBackup(store, schedule)).Start(schedule.Interval)
 	}
 
 	http.HandleFunc("GET /", server.baseHandler)
 	http.HandleFunc("POST /entries", server.newEntryHandler)
+	http.HandleFunc("GET /entries/{id}", server.entryHandler)
+	http.HandleFunc("GET /entries/{id}/similar", server.similarEntriesHandler)
+	http.HandleFunc("PUT /entries/{id}/tags", server.editTagsHandler)
+	http.HandleFunc("POST /entries/{id}/merge", server.mergeEntryHandler)
+	http.HandleFunc("DELETE /entries/{id}", server.deleteEntryHandler)
+	http.HandleFunc("POST /entries/{id}/restore", server.restoreEntryHandler)
+	http.HandleFunc("GET /entries/{id}/history", server.historyHandler)
+	http.HandleFunc("GET /entries/{id}/attachments/{attachment}", server.attachmentHandler)
+	http.HandleFunc("GET /entries/{id}/attachments/{attachment}/thumbnail", server.attachmentThumbnailHandler)
+	http.HandleFunc("POST /entries/{id}/revisions/{revision}/restore", server.restoreRevisionHandler)
+	http.HandleFunc("GET /entries/{id}/reveal", server.revealEntryHandler)
+	http.HandleFunc("POST /entries/{id}/reveal", server.unlockEntryHandler)
+	http.HandleFunc("PUT /entries/{id}/private", server.setEntryPrivateHandler)
+	http.HandleFunc("POST /lock", server.lockHandler)
+	http.HandleFunc("GET /entries/{id}/move", server.moveFormHandler)
+	http.HandleFunc("PUT /entries/{id}/notebook", server.moveEntryHandler)
+	http.HandleFunc("POST /notebooks", server.newNotebookHandler)
+	http.HandleFunc("POST /notebooks/current", server.switchNotebookHandler)
+	http.HandleFunc("GET /entries/{id}/shares", server.sharesHandler)
+	http.HandleFunc("POST /entries/{id}/shares", server.newShareHandler)
+	http.HandleFunc("DELETE /entries/{id}/shares/{share}", server.revokeShareHandler)
+	http.HandleFunc("GET /s/{token}", server.sharedEntryHandler)
+	http.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 	http.HandleFunc("POST /search", server.searchHandler)
+	http.HandleFunc("POST /preview", server.previewHandler)
+
+	http.HandleFunc("GET /on-this-day", server.onThisDayHandler)
+	http.HandleFunc("GET /on-this-day/panel", server.onThisDayPanelHandler)
+
+	http.HandleFunc("GET /calendar", server.calendarHandler)
+	http.HandleFunc("GET /calendar/days/{date}", server.dayEntriesHandler)
+
+	http.HandleFunc("GET /stats", server.statsHandler)
+
+	http.HandleFunc("GET /topics", server.topicsHandler)
+	http.HandleFunc("GET /topics/{id}/entries", server.topicEntriesHandler)
+	http.HandleFunc("POST /topics/recluster", server.reclusterHandler)
+
+	http.HandleFunc("GET /map", server.mapHandler)
+	http.HandleFunc("GET /map/entries/{id}", server.mapEntryHandler)
+
+	http.HandleFunc("GET /trends", server.trendsHandler)
+	http.HandleFunc("POST /trends/themes", server.newThemeHandler)
+	http.HandleFunc("DELETE /trends/themes/{id}", server.deleteThemeHandler)
+
+	http.HandleFunc("POST /import/markdown", server.importMarkdownHandler)
+
+	http.HandleFunc("GET /trash", server.trashHandler)
+	http.HandleFunc("DELETE /trash", server.emptyTrashHandler)
+	http.HandleFunc("DELETE /trash/{id}", server.purgeEntryHandler)
+
+	http.HandleFunc("GET /settings", server.settingsHandler)
+	http.HandleFunc("POST /settings/tokens", server.newTokenHandler)
+	http.HandleFunc("DELETE /settings/tokens/{id}", server.revokeTokenHandler)
+
+	http.HandleFunc("GET /api/entries", server.requireToken(token.ScopeRead, server.apiEntriesHandler))
+	http.HandleFunc("POST /api/entries", server.requireToken(token.ScopeWrite, server.apiNewEntryHandler))
+	http.HandleFunc("GET /api/search", server.requireToken(token.ScopeSearch, server.apiSearchHandler))
+	http.HandleFunc("GET /api/trends", server.requireToken(token.ScopeRead, server.apiTrendsHandler))
+	http.HandleFunc("GET /api/export", server.requireToken(token.ScopeRead, server.exportHandler))
+	http.HandleFunc("GET /api/entries/{id}/attachments/{attachment}", server.requireToken(token.ScopeRead, server.attachmentHandler))
 
 	port := 8080
 	portString := strconv.Itoa(port)
 	log.Println("Listening on port " + portString)
-	log.Fatal(http.ListenAndServe(":"+portString, nil))
+	log.Fatal(http.ListenAndServe(":"+portString, server.Sessions.Protect(http.DefaultServeMux)))
 }
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"spire/entry"
+	"spire/projection"
+	"spire/stats"
+	"spire/storage"
+)
+
+const (
+	// Once this share of entries has arrived since the last fit, the
+	// projection is refitted instead of extended.
+	refitRatio     = 0.2
+	projectionSeed = 42
+
+	mapWidth   = 720
+	mapHeight  = 480
+	mapPadding = 12
+)
+
+const unassignedColor = "#999999"
+
+// refreshMap places new entries using the stored projection, or refits it
+// when enough has changed that the old axes no longer describe the journal.
+func (server *Server) refreshMap() error {
+	model, err := server.Storage.GetProjectionModel()
+	if err != nil {
+		return err
+	}
+
+	pending, err := server.Storage.GetEntriesWithoutPoint()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if model.Size == 0 || float64(len(pending)) > refitRatio*float64(model.Size) {
+		return server.refitMap(model)
+	}
+
+	return server.Storage.AddPoints(projectPoints(model, pending))
+}
+
+// refitMap fits a new projection to every entry, oriented like the previous
+// one so the map doesn't flip around.
+func (server *Server) refitMap(previous projection.Model) error {
+	entries, err := server.Storage.GetEntries()
+	if err != nil {
+		return err
+	}
+
+	vectors := make([]entry.Vector, len(entries))
+	for i, e := range entries {
+		vectors[i] = e.Embedding
+	}
+
+	model := projection.Fit(vectors, projectionSeed).Align(previous)
+
+	return server.Storage.ReplaceProjection(model, projectPoints(model, entries))
+}
+
+func projectPoints(model projection.Model, entries []entry.Entry) []projection.Point {
+	points := make([]projection.Point, len(entries))
+	for i, e := range entries {
+		x, y := model.Project(e.Embedding)
+		points[i] = projection.Point{EntryID: e.ID, X: x, Y: y}
+	}
+
+	return points
+}
+
+type mapPoint struct {
+	ID    int64
+	X     float64
+	Y     float64
+	Color string
+	Date  string
+	Title string
+}
+
+type mapLegendItem struct {
+	Label string
+	Color string
+}
+
+type mapPage struct {
+	CSRFToken string
+	ColorBy   string
+	Width     int
+	Height    int
+	Points    []mapPoint
+	Legend    []mapLegendItem
+}
+
+func (server *Server) mapHandler(w http.ResponseWriter, r *http.Request) {
+	colorBy := r.URL.Query().Get("color")
+	if colorBy != "topic" {
+		colorBy = "time"
+	}
+
+	points, err := server.Storage.GetPoints()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := server.Storage.GetEntries()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := mapPage{ColorBy: colorBy, Width: mapWidth, Height: mapHeight}
+
+	byID := map[int64]entry.Entry{}
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+
+	colors := map[int64]string{}
+	if colorBy == "topic" {
+		colors, page.Legend, err = server.topicColors()
+	} else {
+		colors, page.Legend = timeColors(entries)
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	minX, maxX, minY, maxY := bounds(points)
+	for _, point := range points {
+		e, ok := byID[point.EntryID]
+		if !ok {
+			continue
+		}
+
+		color, ok := colors[e.ID]
+		if !ok {
+			color = unassignedColor
+		}
+
+		page.Points = append(page.Points, mapPoint{
+			ID: e.ID,
+			X:  scale(point.X, minX, maxX, mapPadding, mapWidth-mapPadding),
+			// SVG's y axis points down.
+			Y:     scale(point.Y, minY, maxY, mapHeight-mapPadding, mapPadding),
+			Color: color,
+			Date:  e.Time.In(server.Location).Format("2006-01-02"),
+			Title: entryTitle(e),
+		})
+	}
+
+	page.CSRFToken, err = server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "map.html", page)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// topicColors colors each entry by its topic, with a legend entry per topic.
+func (server *Server) topicColors() (map[int64]string, []mapLegendItem, error) {
+	topics, err := server.Storage.GetTopics()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topicIDs, err := server.Storage.GetEntryTopicIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byTopic := map[int64]string{}
+	var legend []mapLegendItem
+	for i, topic := range topics {
+		color := stats.Palette[i%len(stats.Palette)]
+		byTopic[topic.ID] = color
+		legend = append(legend, mapLegendItem{Label: topic.Label(), Color: color})
+	}
+
+	colors := map[int64]string{}
+	for entryID, topicID := range topicIDs {
+		colors[entryID] = byTopic[topicID]
+	}
+
+	return colors, legend, nil
+}
+
+// timeColors shades entries from blue for the oldest to orange for the
+// newest.
+func timeColors(entries []entry.Entry) (map[int64]string, []mapLegendItem) {
+	colors := map[int64]string{}
+	if len(entries) == 0 {
+		return colors, nil
+	}
+
+	// Entries come newest first.
+	newest, oldest := entries[0].Time, entries[len(entries)-1].Time
+	span := newest.Sub(oldest).Seconds()
+
+	for _, e := range entries {
+		position := 1.0
+		if span > 0 {
+			position = e.Time.Sub(oldest).Seconds() / span
+		}
+
+		colors[e.ID] = timeColor(position)
+	}
+
+	legend := []mapLegendItem{
+		{Label: oldest.Format("Jan 2006"), Color: timeColor(0)},
+		{Label: newest.Format("Jan 2006"), Color: timeColor(1)},
+	}
+
+	return colors, legend
+}
+
+func timeColor(position float64) string {
+	return fmt.Sprintf("hsl(%.0f, 70%%, 50%%)", 220-190*position)
+}
+
+func bounds(points []projection.Point) (minX, maxX, minY, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, point := range points {
+		minX, maxX = min(minX, point.X), max(maxX, point.X)
+		minY, maxY = min(minY, point.Y), max(maxY, point.Y)
+	}
+
+	return minX, maxX, minY, maxY
+}
+
+// scale maps value from [low, high] onto [from, to], putting everything in
+// the middle when there's no spread.
+func scale(value, low, high, from, to float64) float64 {
+	if high <= low {
+		return (from + to) / 2
+	}
+
+	return from + (value-low)/(high-low)*(to-from)
+}
+
+func (server *Server) mapEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	e.Time = e.Time.In(server.Location)
+	err = templates.ExecuteTemplate(w, "map-preview", e)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,46 @@
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// goldmark already drops raw HTML by default, but the sanitizer is the
+// actual safety net, in case an extension or option ever lets some through.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// Prism picks the grammar from the language-* class goldmark adds to
+	// fenced code blocks.
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+
+	// GFM task lists.
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// Render converts CommonMark/GFM to sanitized HTML that's safe to put
+// straight into a template.
+func Render(content string) (template.HTML, error) {
+	var buf bytes.Buffer
+
+	err := renderer.Convert([]byte(content), &buf)
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(policy.SanitizeBytes(buf.Bytes())), nil
+}
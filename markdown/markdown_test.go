@@ -0,0 +1,56 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	actual, err := Render("# Title\n\n- one\n- **two**\n\n```go\nfmt.Println(1)\n```\n\n- [x] done")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<h1>Title</h1>",
+		"<li><strong>two</strong></li>",
+		`<code class="language-go">`,
+		`<input checked="" disabled="" type="checkbox"`,
+	} {
+		if !strings.Contains(string(actual), expected) {
+			t.Errorf("expected %s in %s", expected, actual)
+		}
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	actual, err := Render(`<script>alert(1)</script>
+
+[click](javascript:alert(1)) <img src=x onerror=alert(1)>
+
+<a href="https://example.com" onclick="alert(1)">raw</a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, unexpected := range []string{"<script", "javascript:", "onerror", "onclick"} {
+		if strings.Contains(string(actual), unexpected) {
+			t.Errorf("expected %s to be removed from %s", unexpected, actual)
+		}
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	actual, err := Render("see https://example.com and [notes](/entries/1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(actual), `href="https://example.com"`) {
+		t.Errorf("expected autolinked URL in %s", actual)
+	}
+
+	if !strings.Contains(string(actual), `href="/entries/1"`) {
+		t.Errorf("expected relative link in %s", actual)
+	}
+}
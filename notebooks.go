@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spire/entry"
+	"spire/storage"
+	"strconv"
+)
+
+// Which notebook a browser is writing in and looking at. It's only a
+// preference, so it isn't signed.
+const notebookCookieName = "spire_notebook"
+
+// Searches cover the current notebook unless the scope says otherwise.
+const allNotebooksScope = "all"
+
+// The notebook switcher in the navbar.
+type notebookSwitcher struct {
+	Notebooks []storage.Notebook
+	Current   storage.Notebook
+}
+
+type moveForm struct {
+	Entry     entry.Entry
+	Notebooks []storage.Notebook
+}
+
+// Takes the place of an entry moved to another notebook.
+type movedToast struct {
+	EntryID  int64
+	Notebook storage.Notebook
+}
+
+// currentNotebook is the notebook the browser last switched to, or the
+// default one if it hasn't, or if that notebook is gone.
+func (server *Server) currentNotebook(r *http.Request) (storage.Notebook, error) {
+	if cookie, err := r.Cookie(notebookCookieName); err == nil {
+		id, err := strconv.ParseInt(cookie.Value, 10, 64)
+		if err == nil {
+			notebook, err := server.Storage.GetNotebook(id)
+			if !errors.Is(err, storage.ErrNotebookNotFound) {
+				return notebook, err
+			}
+		}
+	}
+
+	return server.Storage.GetNotebook(storage.DefaultNotebookID)
+}
+
+func (server *Server) loadNotebookSwitcher(r *http.Request) (notebookSwitcher, error) {
+	current, err := server.currentNotebook(r)
+	if err != nil {
+		return notebookSwitcher{}, err
+	}
+
+	notebooks, err := server.Storage.GetNotebooks()
+	if err != nil {
+		return notebookSwitcher{}, err
+	}
+
+	return notebookSwitcher{Notebooks: notebooks, Current: current}, nil
+}
+
+func setNotebookCookie(w http.ResponseWriter, r *http.Request, id int64) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     notebookCookieName,
+		Value:    strconv.FormatInt(id, 10),
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// inNotebook keeps the entries that are in the given notebook.
+func inNotebook(entries []entry.Entry, notebookID int64) []entry.Entry {
+	var result []entry.Entry
+	for _, e := range entries {
+		if e.NotebookID == notebookID {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+func (server *Server) switchNotebookHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	id, err := strconv.ParseInt(r.PostForm.Get("notebook"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid notebook id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = server.Storage.GetNotebook(id)
+	if errors.Is(err, storage.ErrNotebookNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setNotebookCookie(w, r, id)
+	w.Header().Set("HX-Refresh", "true")
+}
+
+// newNotebookHandler creates a notebook and switches to it.
+func (server *Server) newNotebookHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	id, err := server.Storage.CreateNotebook(r.PostForm.Get("name"))
+	if errors.Is(err, storage.ErrNotebookExists) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setNotebookCookie(w, r, id)
+	w.Header().Set("HX-Refresh", "true")
+}
+
+// moveFormHandler fills in an entry's "Move to" menu when it's first opened.
+func (server *Server) moveFormHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	notebooks, err := server.Storage.GetNotebooks()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "move-form", moveForm{Entry: e, Notebooks: notebooks})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// moveEntryHandler puts an entry in another notebook. It keeps its
+// embedding, so nothing is sent to Voyage.
+func (server *Server) moveEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.ParseForm()
+
+	notebookID, err := strconv.ParseInt(r.PostForm.Get("notebook"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid notebook id", http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.MoveEntry(id, notebookID)
+	if errors.Is(err, storage.ErrEntryNotFound) || errors.Is(err, storage.ErrNotebookNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	notebook, err := server.Storage.GetNotebook(notebookID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "moved-toast", movedToast{EntryID: id, Notebook: notebook})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
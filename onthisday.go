@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"spire/entry"
+	"time"
+)
+
+type onThisDay struct {
+	Years     []yearAgo
+	LastMonth []entry.Entry
+	WeekStart time.Time
+	// The last day of the week, for display.
+	WeekEnd time.Time
+}
+
+type yearAgo struct {
+	YearsAgo int
+	Entries  []entry.Entry
+}
+
+type onThisDayPage struct {
+	CSRFToken string
+	Today     time.Time
+	OnThisDay onThisDay
+}
+
+func (server *Server) loadOnThisDay() (onThisDay, error) {
+	now := time.Now().In(server.Location)
+
+	entries, err := server.Storage.GetEntriesOnThisDay(now)
+	if err != nil {
+		return onThisDay{}, err
+	}
+
+	lastMonth, weekStart, weekEnd, err := server.Storage.GetEntriesSameWeekLastMonth(now)
+	if err != nil {
+		return onThisDay{}, err
+	}
+
+	result := onThisDay{
+		LastMonth: server.localize(lastMonth),
+		WeekStart: weekStart,
+		WeekEnd:   weekEnd.AddDate(0, 0, -1),
+	}
+
+	// Entries come back newest first, so each year's group is contiguous.
+	for _, e := range server.localize(entries) {
+		yearsAgo := now.Year() - e.Time.Year()
+
+		if len(result.Years) == 0 || result.Years[len(result.Years)-1].YearsAgo != yearsAgo {
+			result.Years = append(result.Years, yearAgo{YearsAgo: yearsAgo})
+		}
+
+		group := &result.Years[len(result.Years)-1]
+		group.Entries = append(group.Entries, e)
+	}
+
+	return result, nil
+}
+
+// localize shows entry times in the journal's time zone, whatever offset
+// they were saved with.
+func (server *Server) localize(entries []entry.Entry) []entry.Entry {
+	for i := range entries {
+		entries[i].Time = entries[i].Time.In(server.Location)
+	}
+
+	return entries
+}
+
+func (server *Server) onThisDayHandler(w http.ResponseWriter, r *http.Request) {
+	result, err := server.loadOnThisDay()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "on_this_day.html", onThisDayPage{
+		CSRFToken: csrfToken,
+		Today:     time.Now().In(server.Location),
+		OnThisDay: result,
+	})
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// The collapsible panel at the top of the timeline. It's loaded when it's
+// first opened, so the timeline doesn't wait on it.
+func (server *Server) onThisDayPanelHandler(w http.ResponseWriter, r *http.Request) {
+	result, err := server.loadOnThisDay()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "on-this-day-panel", result)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
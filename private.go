@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"spire/entry"
+	"spire/storage"
+	"time"
+)
+
+const (
+	// How long entering the password keeps private entries revealable
+	// without asking again.
+	privateUnlockDuration = 10 * time.Minute
+	// How long a wrong password holds up the response, to slow down guessing.
+	wrongPasswordDelay = time.Second
+)
+
+type unlockForm struct {
+	Entry entry.Entry
+	// Whether there's a password to check against at all.
+	Configured bool
+	Error      string
+}
+
+// unlocked reports whether this request may see private entries.
+func (server *Server) unlocked(r *http.Request) bool {
+	return server.Sessions.Unlocked(r, time.Now())
+}
+
+// hidden reports whether an entry has to stay hidden from this request.
+func (server *Server) hidden(r *http.Request, e entry.Entry) bool {
+	return e.Private && !server.unlocked(r)
+}
+
+// loadEntryFromPath gets the entry named in the path, writing an error
+// response if it can't.
+func (server *Server) loadEntryFromPath(w http.ResponseWriter, r *http.Request) (entry.Entry, bool) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return entry.Entry{}, false
+	}
+
+	e, err := server.Storage.GetEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return entry.Entry{}, false
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return entry.Entry{}, false
+	}
+
+	e.Time = e.Time.In(server.Location)
+	return e, true
+}
+
+// revealEntryHandler shows a private entry in full if the session has been
+// unlocked, and asks for the password otherwise.
+func (server *Server) revealEntryHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if server.hidden(r, e) {
+		server.renderUnlockForm(w, unlockForm{Entry: e})
+		return
+	}
+
+	e.Revealed = true
+	server.renderEntry(w, e)
+}
+
+// unlockEntryHandler checks the password and, if it's right, unlocks the
+// session for a while and shows the entry.
+func (server *Server) unlockEntryHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	r.ParseForm()
+
+	if server.Password == "" {
+		server.renderUnlockForm(w, unlockForm{Entry: e})
+		return
+	}
+
+	password := r.PostForm.Get("password")
+	if subtle.ConstantTimeCompare([]byte(password), []byte(server.Password)) != 1 {
+		time.Sleep(wrongPasswordDelay)
+		server.renderUnlockForm(w, unlockForm{Entry: e, Error: "Wrong password"})
+		return
+	}
+
+	err := server.Sessions.Unlock(w, r, privateUnlockDuration, time.Now())
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	e.Revealed = true
+	server.renderEntry(w, e)
+}
+
+// lockHandler hides private entries again before the unlock runs out.
+func (server *Server) lockHandler(w http.ResponseWriter, r *http.Request) {
+	server.Sessions.Lock(w)
+	w.Header().Set("HX-Refresh", "true")
+}
+
+// setEntryPrivateHandler makes an entry private or public. Anyone can hide an
+// entry, but only an unlocked session can stop hiding one.
+func (server *Server) setEntryPrivateHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	r.ParseForm()
+	private := r.PostForm.Get("private") == "1"
+
+	if !private && server.hidden(r, e) {
+		http.Error(w, "reveal the entry before making it public", http.StatusForbidden)
+		return
+	}
+
+	err := server.Storage.SetEntryPrivate(e.ID, private)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	e.Private = private
+	server.renderEntry(w, e)
+}
+
+func (server *Server) renderUnlockForm(w http.ResponseWriter, form unlockForm) {
+	form.Configured = server.Password != ""
+
+	err := templates.ExecuteTemplate(w, "unlock-form", form)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) renderEntry(w http.ResponseWriter, e entry.Entry) {
+	err := templates.ExecuteTemplate(w, "entry.html", e)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
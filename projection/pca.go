@@ -0,0 +1,176 @@
+package projection
+
+import (
+	"math"
+	"math/rand"
+	"spire/entry"
+)
+
+// Model is a fitted PCA projection from embedding space down to 2D. Keeping
+// it around lets new entries be placed on the map without refitting.
+type Model struct {
+	Mean entry.Vector
+	// Unit-length principal axes, the first explaining the most variance.
+	Axes []entry.Vector
+	// How many vectors the model was fitted to.
+	Size int
+}
+
+// Point is an entry's position on the map.
+type Point struct {
+	EntryID int64
+	X       float64
+	Y       float64
+}
+
+const (
+	dimensions      = 2
+	powerIterations = 100
+	// Stop iterating once an axis moves less than this between rounds.
+	convergence = 1e-9
+)
+
+// Fit finds the two directions along which the vectors vary the most. It
+// uses power iteration, which only ever multiplies by the data, so the
+// covariance matrix of the 512-dimensional embeddings is never built.
+func Fit(vectors []entry.Vector, seed int64) Model {
+	if len(vectors) == 0 {
+		return Model{}
+	}
+
+	mean := entry.Mean(vectors)
+	centered := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		centered[i] = make([]float64, len(mean))
+		for j := range mean {
+			centered[i][j] = float64(v[j]) - float64(mean[j])
+		}
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	var axes [][]float64
+
+	for len(axes) < dimensions {
+		axis := make([]float64, len(mean))
+		for j := range axis {
+			axis[j] = random.NormFloat64()
+		}
+		orthogonalize(axis, axes)
+		if normalize(axis) == 0 {
+			break
+		}
+
+		for iteration := 0; iteration < powerIterations; iteration++ {
+			next := covarianceTimes(centered, axis)
+			orthogonalize(next, axes)
+
+			// Nothing left to explain, for example when every vector is
+			// the same.
+			if normalize(next) == 0 {
+				break
+			}
+
+			delta := 0.0
+			for j := range next {
+				delta += (next[j] - axis[j]) * (next[j] - axis[j])
+			}
+
+			axis = next
+			if delta < convergence {
+				break
+			}
+		}
+
+		axes = append(axes, axis)
+	}
+
+	model := Model{Mean: mean, Size: len(vectors)}
+	for _, axis := range axes {
+		v := make(entry.Vector, len(axis))
+		for j, value := range axis {
+			v[j] = float32(value)
+		}
+		model.Axes = append(model.Axes, v)
+	}
+
+	return model
+}
+
+// Project places v on the map. Axes the model couldn't find stay at zero.
+func (m Model) Project(v entry.Vector) (float64, float64) {
+	var coordinates [dimensions]float64
+	for i, axis := range m.Axes {
+		for j := range min(len(axis), len(v), len(m.Mean)) {
+			coordinates[i] += (float64(v[j]) - float64(m.Mean[j])) * float64(axis[j])
+		}
+	}
+
+	return coordinates[0], coordinates[1]
+}
+
+// Align flips axes to point the same way as previous's. An axis and its
+// opposite explain the same variance, so without this a refit could mirror
+// the whole map.
+func (m Model) Align(previous Model) Model {
+	for i := range min(len(m.Axes), len(previous.Axes)) {
+		if m.Axes[i].Dot(previous.Axes[i]) < 0 {
+			flipped := make(entry.Vector, len(m.Axes[i]))
+			for j, value := range m.Axes[i] {
+				flipped[j] = -value
+			}
+			m.Axes[i] = flipped
+		}
+	}
+
+	return m
+}
+
+// covarianceTimes computes Xᵀ(Xv) for the centered data X.
+func covarianceTimes(centered [][]float64, v []float64) []float64 {
+	result := make([]float64, len(v))
+	for _, row := range centered {
+		var dot float64
+		for j, value := range row {
+			dot += value * v[j]
+		}
+		for j, value := range row {
+			result[j] += dot * value
+		}
+	}
+
+	return result
+}
+
+// orthogonalize removes the components of v along each of the unit-length
+// axes.
+func orthogonalize(v []float64, axes [][]float64) {
+	for _, axis := range axes {
+		var dot float64
+		for j := range v {
+			dot += v[j] * axis[j]
+		}
+		for j := range v {
+			v[j] -= dot * axis[j]
+		}
+	}
+}
+
+// normalize scales v to unit length in place and returns its original
+// length.
+func normalize(v []float64) float64 {
+	var sum float64
+	for _, value := range v {
+		sum += value * value
+	}
+
+	norm := math.Sqrt(sum)
+	if norm < 1e-12 {
+		return 0
+	}
+
+	for j := range v {
+		v[j] /= norm
+	}
+
+	return norm
+}
@@ -0,0 +1,65 @@
+package projection
+
+import (
+	"math"
+	"spire/entry"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	// Spread mostly along the first dimension, a little along the second
+	// and not at all along the third.
+	vectors := []entry.Vector{
+		{-4, 1, 5},
+		{-2, -1, 5},
+		{0, 0, 5},
+		{2, -1, 5},
+		{4, 1, 5},
+	}
+
+	model := Fit(vectors, 42)
+
+	if len(model.Axes) != 2 || model.Size != 5 {
+		t.Fatalf("expected 2 axes fitted to 5 vectors, got %v", model)
+	}
+
+	if math.Abs(float64(model.Axes[0][0])) < 0.99 {
+		t.Errorf("expected the first axis along the first dimension, got %v", model.Axes[0])
+	}
+
+	if math.Abs(float64(model.Axes[1][1])) < 0.99 {
+		t.Errorf("expected the second axis along the second dimension, got %v", model.Axes[1])
+	}
+
+	x, y := model.Project(entry.Vector{0, 0, 5})
+	if math.Abs(x) > 1e-6 || math.Abs(y) > 1e-6 {
+		t.Errorf("expected the mean to project to the origin, got (%v, %v)", x, y)
+	}
+
+	left, _ := model.Project(vectors[0])
+	right, _ := model.Project(vectors[4])
+	if math.Abs(left-right) < 7.9 {
+		t.Errorf("expected the ends 8 apart along the first axis, got %v and %v", left, right)
+	}
+}
+
+func TestAlign(t *testing.T) {
+	previous := Model{Axes: []entry.Vector{{1, 0}, {0, 1}}}
+	model := Model{Mean: entry.Vector{0, 0}, Axes: []entry.Vector{{-1, 0}, {0, 1}}}
+
+	aligned := model.Align(previous)
+
+	x, y := aligned.Project(entry.Vector{2, 3})
+	if x != 2 || y != 3 {
+		t.Errorf("expected the flipped axis to be restored, got (%v, %v)", x, y)
+	}
+}
+
+func TestFitIdentical(t *testing.T) {
+	model := Fit([]entry.Vector{{1, 2}, {1, 2}}, 42)
+
+	x, y := model.Project(entry.Vector{1, 2})
+	if x != 0 || y != 0 {
+		t.Errorf("expected identical vectors at the origin, got (%v, %v)", x, y)
+	}
+}
@@ -0,0 +1,101 @@
+package seal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	key, params, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatalf("error making key: %v\n", err)
+	}
+
+	sealed, err := key.Seal("walked the dog in the park #life", "entries.content:1")
+	if err != nil {
+		t.Fatalf("error sealing: %v\n", err)
+	}
+
+	if !IsSealed(sealed) || strings.Contains(sealed, "dog") {
+		t.Fatalf("sealed text doesn't look sealed: %q\n", sealed)
+	}
+
+	again, err := key.Seal("walked the dog in the park #life", "entries.content:1")
+	if err != nil {
+		t.Fatalf("error sealing: %v\n", err)
+	}
+
+	if again == sealed {
+		t.Error("sealing the same text twice gave the same result")
+	}
+
+	unlocked, err := Unlock("correct horse", params)
+	if err != nil {
+		t.Fatalf("error unlocking: %v\n", err)
+	}
+
+	opened, err := unlocked.Open(sealed, "entries.content:1")
+	if err != nil {
+		t.Fatalf("error opening: %v\n", err)
+	}
+
+	if opened != "walked the dog in the park #life" {
+		t.Errorf("expected the original text, got %q\n", opened)
+	}
+}
+
+func TestUnlockWrongPassphrase(t *testing.T) {
+	_, params, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatalf("error making key: %v\n", err)
+	}
+
+	_, err = Unlock("battery staple", params)
+	if !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v\n", err)
+	}
+}
+
+func TestOpenWithOtherKey(t *testing.T) {
+	first, _, err := NewKey("first")
+	if err != nil {
+		t.Fatalf("error making key: %v\n", err)
+	}
+
+	second, _, err := NewKey("second")
+	if err != nil {
+		t.Fatalf("error making key: %v\n", err)
+	}
+
+	sealed, err := first.Seal("hello", "entries.content:1")
+	if err != nil {
+		t.Fatalf("error sealing: %v\n", err)
+	}
+
+	for _, text := range []string{sealed, sealed[:len(sealed)-4], "hello", Prefix + "!!"} {
+		_, err = second.Open(text, "entries.content:1")
+		if !errors.Is(err, ErrCorrupt) {
+			t.Errorf("expected ErrCorrupt opening %q, got %v\n", text, err)
+		}
+	}
+}
+
+func TestOpenWithOtherLabel(t *testing.T) {
+	key, _, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatalf("error making key: %v\n", err)
+	}
+
+	sealed, err := key.Seal("hello", "entries.content:1")
+	if err != nil {
+		t.Fatalf("error sealing: %v\n", err)
+	}
+
+	for _, label := range []string{"entries.content:2", "entry_revisions.content:1", ""} {
+		_, err = key.Open(sealed, label)
+		if !errors.Is(err, ErrCorrupt) {
+			t.Errorf("expected ErrCorrupt opening with label %q, got %v\n", label, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	CSRFHeader    = "X-CSRF-Token"
+	CSRFFormField = "csrf_token"
+	// Where the routes that authenticate with bearer tokens live.
+	APIPrefix = "/api/"
+)
+
+// CSRFToken returns the token that unsafe requests from this session must
+// carry, starting a session if needed.
+func (m *Manager) CSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	id, err := m.ID(w, r)
+	if err != nil {
+		return "", err
+	}
+
+	return m.sign("csrf", id), nil
+}
+
+// Protect rejects unsafe requests that don't carry the session's CSRF token.
+// As a second line of defense, it also rejects requests that the browser
+// says came from another site.
+func (m *Manager) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Bearer tokens have to be attached explicitly, so a cross-site page
+		// can't make the browser send one on its behalf. Only the API checks
+		// them, though; everywhere else a cookie is what authenticates.
+		if strings.HasPrefix(r.URL.Path, APIPrefix) && strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !sameSite(r) {
+			http.Error(w, "cross-site request rejected", http.StatusForbidden)
+			return
+		}
+
+		id, ok := existingID(r)
+		if !ok {
+			http.Error(w, "missing session", http.StatusForbidden)
+			return
+		}
+
+		provided := r.Header.Get(CSRFHeader)
+		if provided == "" {
+			provided = r.PostFormValue(CSRFFormField)
+		}
+
+		expected := m.sign("csrf", id)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+			http.Error(w, "invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameSite checks Sec-Fetch-Site and Origin when the browser sends them.
+// Older browsers send neither, in which case the token check has to carry
+// the weight on its own.
+func sameSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	return strings.EqualFold(parsed.Host, r.Host)
+}
@@ -0,0 +1,206 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestHandler() (*Manager, http.Handler) {
+	manager := NewManager([]byte("test secret"))
+	handler := manager.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return manager, handler
+}
+
+// startSession does what a page load would: it hands the browser a session
+// cookie and the matching CSRF token.
+func startSession(t *testing.T, manager *Manager) (*http.Cookie, string) {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/", nil)
+
+	csrfToken, err := manager.CSRFToken(recorder, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CookieName {
+		t.Fatalf("expected a session cookie, got %v", cookies)
+	}
+
+	return cookies[0], csrfToken
+}
+
+func TestProtect(t *testing.T) {
+	manager, handler := newTestHandler()
+	cookie, csrfToken := startSession(t, manager)
+	_, otherToken := startSession(t, manager)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		setup    func(r *http.Request)
+		expected int
+	}{
+		{
+			name:     "safe methods are allowed without a token",
+			method:   "GET",
+			setup:    func(r *http.Request) {},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "post without a session is rejected",
+			method:   "POST",
+			setup:    func(r *http.Request) {},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:   "post without a token is rejected",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:   "post with the header token is allowed",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, csrfToken)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:   "delete with the header token is allowed",
+			method: "DELETE",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, csrfToken)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:   "token from another session is rejected",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, otherToken)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:   "cross-site fetch is rejected even with a token",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, csrfToken)
+				r.Header.Set("Sec-Fetch-Site", "cross-site")
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:   "same-origin fetch is allowed",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, csrfToken)
+				r.Header.Set("Sec-Fetch-Site", "same-origin")
+				r.Header.Set("Origin", "http://example.com")
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:   "foreign origin is rejected even with a token",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set(CSRFHeader, csrfToken)
+				r.Header.Set("Origin", "https://evil.example")
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:   "bearer token requests to the API are exempt",
+			method: "POST",
+			path:   "/api/entries",
+			setup: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer spire_abc")
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:   "bearer token requests elsewhere are not",
+			method: "POST",
+			setup: func(r *http.Request) {
+				r.AddCookie(cookie)
+				r.Header.Set("Authorization", "Bearer spire_abc")
+			},
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := test.path
+			if path == "" {
+				path = "/entries"
+			}
+
+			request := httptest.NewRequest(test.method, "http://example.com"+path, nil)
+			test.setup(request)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.expected {
+				t.Errorf("expected status %d, got %d", test.expected, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestProtectFormField(t *testing.T) {
+	manager, handler := newTestHandler()
+	cookie, csrfToken := startSession(t, manager)
+
+	form := url.Values{"entry": {"hello"}, CSRFFormField: {csrfToken}}
+	request := httptest.NewRequest("POST", "/entries", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(cookie)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestCSRFTokenReusesSession(t *testing.T) {
+	manager, _ := newTestHandler()
+	cookie, csrfToken := startSession(t, manager)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/", nil)
+	request.AddCookie(cookie)
+
+	again, err := manager.CSRFToken(recorder, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != csrfToken {
+		t.Errorf("expected the same token for the same session")
+	}
+
+	if len(recorder.Result().Cookies()) != 0 {
+		t.Errorf("expected no new cookie for an existing session")
+	}
+}
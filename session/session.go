@@ -0,0 +1,84 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+)
+
+const CookieName = "spire_session"
+
+// Manager hands out anonymous session cookies and derives per-session secrets
+// from them. Nothing is stored server-side; everything is recomputed from the
+// session ID and the manager's secret.
+type Manager struct {
+	secret []byte
+}
+
+func NewManager(secret []byte) *Manager {
+	return &Manager{secret: secret}
+}
+
+// A random secret means sessions don't survive a restart, which is fine for
+// development but annoying otherwise.
+func NewRandomSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// ID returns the request's session ID, starting a new session if there isn't
+// one yet.
+func (m *Manager) ID(w http.ResponseWriter, r *http.Request) (string, error) {
+	if id, ok := existingID(r); ok {
+		return id, nil
+	}
+
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(buf)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// Make the new session visible to anything else handling this request.
+	r.AddCookie(&http.Cookie{Name: CookieName, Value: id})
+
+	return id, nil
+}
+
+func existingID(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+
+	return cookie.Value, true
+}
+
+// sign derives a value from the session ID that can't be computed without the
+// secret. The purpose keeps values for different uses from being swapped.
+func (m *Manager) sign(purpose string, id string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(id))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,71 @@
+package session
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const UnlockCookieName = "spire_unlock"
+
+// Unlock marks the session as having re-entered the password, until ttl
+// from now. The cookie carries its own expiry, signed along with the session
+// ID, so it can't be extended or moved to another session.
+func (m *Manager) Unlock(w http.ResponseWriter, r *http.Request, ttl time.Duration, now time.Time) error {
+	id, err := m.ID(w, r)
+	if err != nil {
+		return err
+	}
+
+	expires := strconv.FormatInt(now.Add(ttl).Unix(), 10)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     UnlockCookieName,
+		Value:    expires + "." + m.sign("unlock", id+"."+expires),
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return nil
+}
+
+// Unlocked reports whether the session unlocked itself recently enough.
+func (m *Manager) Unlocked(r *http.Request, now time.Time) bool {
+	id, ok := existingID(r)
+	if !ok {
+		return false
+	}
+
+	cookie, err := r.Cookie(UnlockCookieName)
+	if err != nil {
+		return false
+	}
+
+	expires, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return false
+	}
+
+	expected := m.sign("unlock", id+"."+expires)
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(expected)) != 1 {
+		return false
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	return err == nil && now.Before(time.Unix(unix, 0))
+}
+
+// Lock ends an unlock early.
+func (m *Manager) Lock(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   UnlockCookieName,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
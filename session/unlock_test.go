@@ -0,0 +1,61 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUnlock(t *testing.T) {
+	manager := NewManager([]byte("test secret"))
+	sessionCookie, _ := startSession(t, manager)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.AddCookie(sessionCookie)
+
+	if manager.Unlocked(request, now) {
+		t.Fatal("expected a new session to be locked")
+	}
+
+	recorder := httptest.NewRecorder()
+	err := manager.Unlock(recorder, request, 5*time.Minute, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != UnlockCookieName {
+		t.Fatalf("expected an unlock cookie, got %v", cookies)
+	}
+	unlockCookie := cookies[0]
+
+	withCookies := func(cookies ...*http.Cookie) *http.Request {
+		request := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		return request
+	}
+
+	if !manager.Unlocked(withCookies(sessionCookie, unlockCookie), now.Add(4*time.Minute)) {
+		t.Error("expected the session to be unlocked within the time limit")
+	}
+
+	if manager.Unlocked(withCookies(sessionCookie, unlockCookie), now.Add(5*time.Minute)) {
+		t.Error("expected the unlock to expire")
+	}
+
+	otherSession, _ := startSession(t, manager)
+	if manager.Unlocked(withCookies(otherSession, unlockCookie), now) {
+		t.Error("expected the unlock not to work for another session")
+	}
+
+	extended := *unlockCookie
+	extended.Value = "9999999999" + unlockCookie.Value[len("1717243500"):]
+	if manager.Unlocked(withCookies(sessionCookie, &extended), now) {
+		t.Error("expected a changed expiry to be rejected")
+	}
+}
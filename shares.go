@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"spire/entry"
+	"spire/markdown"
+	"spire/storage"
+	"spire/token"
+	"strconv"
+	"time"
+)
+
+// How many of a share link's views are listed under it.
+const shareViewsShown = 10
+
+type sharesPanel struct {
+	Entry  entry.Entry
+	Shares []shareRow
+	// The link just created, shown once since only its hash is kept.
+	NewLink string
+	Error   string
+}
+
+type shareRow struct {
+	storage.Share
+	Active      bool
+	RecentViews []storage.ShareView
+}
+
+// What the public page gets: the entry's time and content, and nothing that
+// points at the rest of the journal.
+type sharedEntryPage struct {
+	Time    time.Time
+	Content template.HTML
+}
+
+// sharesHandler fills in an entry's "Share" panel when it's first opened.
+func (server *Server) sharesHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if server.hidden(r, e) {
+		http.Error(w, "reveal the entry to share it", http.StatusForbidden)
+		return
+	}
+
+	server.renderSharesPanel(w, sharesPanel{Entry: e})
+}
+
+// newShareHandler makes a new share link for an entry, expiring after the
+// given number of days, or never for zero.
+func (server *Server) newShareHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	r.ParseForm()
+
+	days, err := strconv.Atoi(r.PostForm.Get("expires_in_days"))
+	if err != nil || days < 0 {
+		http.Error(w, "invalid expiry", http.StatusBadRequest)
+		return
+	}
+
+	if e.Private {
+		server.renderSharesPanel(w, sharesPanel{Entry: e, Error: "Make the entry public before sharing it."})
+		return
+	}
+
+	plaintext, hash, err := token.GenerateWithPrefix(token.SharePrefix)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+	if days > 0 {
+		expires := now.AddDate(0, 0, days)
+		expiresAt = &expires
+	}
+
+	_, err = server.Storage.CreateShare(e.ID, hash, now, expiresAt)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderSharesPanel(w, sharesPanel{Entry: e, NewLink: shareURL(r, plaintext)})
+}
+
+func (server *Server) revokeShareHandler(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.loadEntryFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("share"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid share id", http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.RevokeShare(e.ID, id, time.Now())
+	if errors.Is(err, storage.ErrShareNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderSharesPanel(w, sharesPanel{Entry: e})
+}
+
+// sharedEntryHandler is the public page behind a share link. Anything wrong
+// with the link, including the entry having been trashed or made private
+// since, gets the same 404, so the page says nothing about the journal.
+func (server *Server) sharedEntryHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+
+	share, err := server.Storage.GetShareByHash(token.Hash(r.PathValue("token")))
+	if errors.Is(err, storage.ErrShareNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if !share.Active(now) {
+		log.Printf("refused share link %d for entry %d from %s: revoked or expired\n", share.ID, share.EntryID, r.RemoteAddr)
+		http.NotFound(w, r)
+		return
+	}
+
+	e, err := server.Storage.GetEntry(share.EntryID)
+	if errors.Is(err, storage.ErrEntryNotFound) || (err == nil && e.Private) {
+		log.Printf("refused share link %d for entry %d from %s: entry is gone or private\n", share.ID, share.EntryID, r.RemoteAddr)
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	// [[links]] are left as written; their targets aren't shared.
+	content, err := markdown.Render(e.Content)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	err = server.Storage.LogShareView(share.ID, storage.ShareView{
+		ViewedAt:   now,
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+	})
+	if err != nil {
+		// Not worth failing the request over.
+		log.Printf("error logging share view: %v\n", err)
+	}
+
+	// The token is in the URL, so keep it out of caches, Referer headers and
+	// search engines.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	err = templates.ExecuteTemplate(w, "share.html", sharedEntryPage{
+		Time:    e.Time.In(server.Location),
+		Content: content,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (server *Server) renderSharesPanel(w http.ResponseWriter, panel sharesPanel) {
+	shares, err := server.Storage.GetShares(panel.Entry.ID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	for _, share := range shares {
+		views, err := server.Storage.GetShareViews(share.ID, shareViewsShown)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		panel.Shares = append(panel.Shares, shareRow{Share: share, Active: share.Active(now), RecentViews: views})
+	}
+
+	err = templates.ExecuteTemplate(w, "shares", panel)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// shareURL is the full link to hand out, on whatever host the journal was
+// reached at.
+func shareURL(r *http.Request, plaintext string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + "/s/" + plaintext
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"spire/stats"
+	"strconv"
+)
+
+// How many of the most frequent terms to show.
+const topTermsLimit = 25
+
+type statsPage struct {
+	CSRFToken    string
+	Stats        stats.Stats
+	WeekdayChart template.HTML
+	HourChart    template.HTML
+	MonthlyChart template.HTML
+	GrowthChart  template.HTML
+	MaxTermCount int
+}
+
+func (server *Server) statsHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := server.Storage.GetEntryTexts()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range entries {
+		entries[i].Revealed = !server.hidden(r, entries[i])
+	}
+
+	result := stats.Compute(entries, server.Location, topTermsLimit)
+
+	weekdays := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+	hours := make([]string, 24)
+	for hour := range hours {
+		hours[hour] = strconv.Itoa(hour)
+	}
+
+	months := make([]string, len(result.Growth))
+	monthly := make([]int, len(result.Growth))
+	cumulative := make([]int, len(result.Growth))
+	for i, month := range result.Growth {
+		months[i] = month.Month.Format("Jan '06")
+		monthly[i] = month.Entries
+		cumulative[i] = month.Cumulative
+	}
+
+	// Aim for about eight labels on the month axis.
+	monthLabelEvery := max(1, len(months)/8)
+
+	page := statsPage{
+		Stats:        result,
+		WeekdayChart: stats.BarChart("Entries per weekday", weekdays, result.ByWeekday[:], 1),
+		HourChart:    stats.BarChart("Entries per hour of the day", hours, result.ByHour[:], 3),
+		MonthlyChart: stats.BarChart("Entries per month", months, monthly, monthLabelEvery),
+		GrowthChart:  stats.LineChart("Total entries over time", months, cumulative, monthLabelEvery),
+	}
+
+	if len(result.TopTerms) > 0 {
+		page.MaxTermCount = result.TopTerms[0].Count
+	}
+
+	page.CSRFToken, err = server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "stats.html", page)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
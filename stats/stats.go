@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"sort"
+	"spire/entry"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type TermCount struct {
+	Term  string
+	Count int
+}
+
+type MonthCount struct {
+	// The first day of the month.
+	Month      time.Time
+	Entries    int
+	Cumulative int
+}
+
+type Stats struct {
+	TotalEntries int
+	TotalWords   int
+	AverageWords float64
+	// Monday first.
+	ByWeekday [7]int
+	ByHour    [24]int
+	TopTerms  []TermCount
+	Growth    []MonthCount
+}
+
+// Compute gathers writing statistics over the given entries. Weekdays, hours
+// and months are taken in loc. Hidden entries count towards everything but
+// the top terms, which would give their words away.
+func Compute(entries []entry.Entry, loc *time.Location, topTerms int) Stats {
+	var result Stats
+	terms := map[string]int{}
+	byMonth := map[time.Time]int{}
+
+	for _, e := range entries {
+		written := e.Time.In(loc)
+		words := strings.Fields(e.Content)
+
+		result.TotalEntries++
+		result.TotalWords += len(words)
+		result.ByWeekday[(int(written.Weekday())+6)%7]++
+		result.ByHour[written.Hour()]++
+
+		month := time.Date(written.Year(), written.Month(), 1, 0, 0, 0, 0, loc)
+		byMonth[month]++
+
+		if e.Hidden() {
+			continue
+		}
+
+		for _, term := range Terms(e.Content) {
+			terms[term]++
+		}
+	}
+
+	if result.TotalEntries > 0 {
+		result.AverageWords = float64(result.TotalWords) / float64(result.TotalEntries)
+	}
+
+	result.TopTerms = topCounts(terms, topTerms)
+	result.Growth = growth(byMonth)
+
+	return result
+}
+
+// Terms splits content into lowercase words, leaving out stopwords, numbers
+// and anything shorter than three letters.
+func Terms(content string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+
+	var terms []string
+	for _, field := range fields {
+		field = strings.Trim(field, "'")
+		field = strings.TrimSuffix(field, "'s")
+
+		if len([]rune(field)) < 3 || stopwords[field] {
+			continue
+		}
+
+		if !strings.ContainsFunc(field, unicode.IsLetter) {
+			continue
+		}
+
+		terms = append(terms, field)
+	}
+
+	return terms
+}
+
+func topCounts(counts map[string]int, limit int) []TermCount {
+	result := make([]TermCount, 0, len(counts))
+	for term, count := range counts {
+		result = append(result, TermCount{Term: term, Count: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+
+		return result[i].Term < result[j].Term
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result
+}
+
+// growth fills in months without entries, so the chart's x axis is even.
+func growth(byMonth map[time.Time]int) []MonthCount {
+	if len(byMonth) == 0 {
+		return nil
+	}
+
+	var first, last time.Time
+	for month := range byMonth {
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+
+		if last.IsZero() || month.After(last) {
+			last = month
+		}
+	}
+
+	var result []MonthCount
+	cumulative := 0
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		cumulative += byMonth[month]
+		result = append(result, MonthCount{
+			Month:      month,
+			Entries:    byMonth[month],
+			Cumulative: cumulative,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"slices"
+	"spire/entry"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	entries := []entry.Entry{
+		// A Monday morning.
+		{Time: time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC), Content: "The garden is growing, garden beds need water"},
+		// A Wednesday evening, two months later.
+		{Time: time.Date(2025, 3, 5, 21, 0, 0, 0, time.UTC), Content: "Garden again and the dog's walk"},
+		// Private, so only counted.
+		{Time: time.Date(2025, 3, 6, 21, 0, 0, 0, time.UTC), Content: "secret secret secret secret", Private: true},
+	}
+
+	result := Compute(entries, time.UTC, 3)
+
+	if result.TotalEntries != 3 || result.TotalWords != 18 || result.AverageWords != 6 {
+		t.Errorf("unexpected totals %d entries, %d words, %f average", result.TotalEntries, result.TotalWords, result.AverageWords)
+	}
+
+	if result.ByWeekday[0] != 1 || result.ByWeekday[2] != 1 {
+		t.Errorf("expected one entry on Monday and one on Wednesday, got %v", result.ByWeekday)
+	}
+
+	if result.ByHour[9] != 1 || result.ByHour[21] != 2 {
+		t.Errorf("expected entries at 9 and 21, got %v", result.ByHour)
+	}
+
+	if len(result.TopTerms) != 3 || result.TopTerms[0] != (TermCount{Term: "garden", Count: 3}) || slices.ContainsFunc(result.TopTerms, func(c TermCount) bool { return c.Term == "secret" }) {
+		t.Errorf("expected garden to be the top term and no private terms, got %v", result.TopTerms)
+	}
+
+	if len(result.Growth) != 3 || result.Growth[1].Entries != 0 || result.Growth[2].Cumulative != 3 {
+		t.Errorf("expected three months of growth with an empty February, got %v", result.Growth)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	actual := Terms("I'm sure the dog's bowl is in 2025, it's #bowl-shaped")
+	expected := []string{"sure", "dog", "bowl", "bowl", "shaped"}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestBarChartEscapes(t *testing.T) {
+	chart := string(BarChart("<title>", []string{"<b>"}, []int{1}, 1))
+
+	if strings.Contains(chart, "<b>") || strings.Contains(chart, "<title><title>") {
+		t.Errorf("expected labels to be escaped, got %s", chart)
+	}
+
+	if !strings.HasPrefix(chart, "<svg") || !strings.HasSuffix(chart, "</svg>") {
+		t.Errorf("expected a complete svg, got %s", chart)
+	}
+}
+
+func TestMultiLineChart(t *testing.T) {
+	chart := string(MultiLineChart(
+		"Themes",
+		[]string{"Jan", "Feb"},
+		[]ChartSeries{
+			{Name: "<work>", Values: []float64{0.2, 0.4}},
+			{Name: "garden", Values: []float64{0.3, 0.1}},
+		},
+		1,
+	))
+
+	if strings.Count(chart, "<polyline") != 2 {
+		t.Errorf("expected a line per series, got %s", chart)
+	}
+
+	if strings.Contains(chart, "<work>") {
+		t.Errorf("expected series names to be escaped, got %s", chart)
+	}
+
+	if !strings.Contains(chart, Palette[1]) {
+		t.Errorf("expected the second series in the second palette color, got %s", chart)
+	}
+}
@@ -0,0 +1,222 @@
+package stats
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Charts are plain SVG built on the server, so they need no JavaScript and
+// pick up the page's colors through currentColor and CSS variables.
+
+const (
+	chartHeight  = 160
+	axisHeight   = 20
+	valueHeight  = 14
+	minBarWidth  = 12
+	chartPadding = 4
+)
+
+// BarChart draws one labeled bar per value. Every labelEvery-th label is
+// shown, so dense charts stay readable.
+func BarChart(title string, labels []string, values []int, labelEvery int) template.HTML {
+	maxValue := maxOf(values)
+	barWidth := max(minBarWidth, 480/max(len(values), 1))
+	width := len(values)*barWidth + 2*chartPadding
+	plotHeight := chartHeight - axisHeight - valueHeight
+
+	var b strings.Builder
+	openSVG(&b, title, width)
+
+	for i, value := range values {
+		barHeight := 0
+		if maxValue > 0 {
+			barHeight = value * plotHeight / maxValue
+		}
+
+		x := chartPadding + i*barWidth
+		y := valueHeight + plotHeight - barHeight
+
+		fmt.Fprintf(&b,
+			`<rect x="%d" y="%d" width="%d" height="%d" rx="2" class="bar"><title>%s: %d</title></rect>`,
+			x+1, y, barWidth-2, barHeight, html.EscapeString(labels[i]), value,
+		)
+
+		if value > 0 && barWidth >= 20 {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%d</text>`, x+barWidth/2, y-3, value)
+		}
+
+		if labelEvery > 0 && i%labelEvery == 0 {
+			fmt.Fprintf(&b,
+				`<text x="%d" y="%d" text-anchor="middle">%s</text>`,
+				x+barWidth/2, chartHeight-6, html.EscapeString(labels[i]),
+			)
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// LineChart draws values as a filled line, for things like cumulative
+// counts over time.
+func LineChart(title string, labels []string, values []int, labelEvery int) template.HTML {
+	maxValue := maxOf(values)
+	step := max(minBarWidth, 480/max(len(values), 1))
+	width := max(len(values)-1, 1)*step + 2*chartPadding
+	plotHeight := chartHeight - axisHeight - valueHeight
+	baseline := valueHeight + plotHeight
+
+	point := func(i int, value int) (int, int) {
+		y := baseline
+		if maxValue > 0 {
+			y = baseline - value*plotHeight/maxValue
+		}
+
+		return chartPadding + i*step, y
+	}
+
+	var b strings.Builder
+	openSVG(&b, title, width)
+
+	if len(values) > 0 {
+		var line strings.Builder
+		for i, value := range values {
+			x, y := point(i, value)
+			fmt.Fprintf(&line, "%d,%d ", x, y)
+		}
+
+		firstX, _ := point(0, 0)
+		lastX, _ := point(len(values)-1, 0)
+		fmt.Fprintf(&b,
+			`<polygon points="%d,%d %s%d,%d" class="area" />`,
+			firstX, baseline, line.String(), lastX, baseline,
+		)
+		fmt.Fprintf(&b, `<polyline points="%s" class="line" />`, strings.TrimSpace(line.String()))
+
+		for i, value := range values {
+			x, y := point(i, value)
+			fmt.Fprintf(&b,
+				`<circle cx="%d" cy="%d" r="3" class="point"><title>%s: %d</title></circle>`,
+				x, y, html.EscapeString(labels[i]), value,
+			)
+
+			if labelEvery > 0 && i%labelEvery == 0 {
+				fmt.Fprintf(&b,
+					`<text x="%d" y="%d" text-anchor="middle">%s</text>`,
+					x, chartHeight-6, html.EscapeString(labels[i]),
+				)
+			}
+		}
+
+		_, topY := point(0, maxValue)
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%d</text>`, chartPadding, topY-3, maxValue)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Palette colors charts and maps with more than one series, in order. It's
+// colorblind-safe for the first several entries.
+var Palette = []string{
+	"#4477aa", "#ee6677", "#228833", "#ccbb44", "#66ccee", "#aa3377",
+	"#bb5566", "#004488", "#ddaa33", "#117733", "#882255", "#44aa99",
+}
+
+// ChartSeries is one named line on a MultiLineChart.
+type ChartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// MultiLineChart draws several series over the same labels, each in its own
+// Palette color. The y axis spans just the range of the values, since small
+// differences are what's interesting when comparing series.
+func MultiLineChart(title string, labels []string, series []ChartSeries, labelEvery int) template.HTML {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, value := range s.Values {
+			low, high = min(low, value), max(high, value)
+		}
+	}
+
+	step := max(minBarWidth, 480/max(len(labels), 1))
+	width := max(len(labels)-1, 1)*step + 2*chartPadding
+	plotHeight := chartHeight - axisHeight - valueHeight
+	baseline := valueHeight + plotHeight
+
+	point := func(i int, value float64) (int, int) {
+		y := baseline - plotHeight/2
+		if high > low {
+			y = baseline - int((value-low)/(high-low)*float64(plotHeight))
+		}
+
+		return chartPadding + i*step, y
+	}
+
+	var b strings.Builder
+	openSVG(&b, title, width)
+
+	for i, s := range series {
+		if len(s.Values) == 0 {
+			continue
+		}
+
+		color := Palette[i%len(Palette)]
+
+		var line strings.Builder
+		for j, value := range s.Values {
+			x, y := point(j, value)
+			fmt.Fprintf(&line, "%d,%d ", x, y)
+		}
+
+		fmt.Fprintf(&b,
+			`<polyline points="%s" class="series" stroke="%s"><title>%s</title></polyline>`,
+			strings.TrimSpace(line.String()), color, html.EscapeString(s.Name),
+		)
+
+		for j, value := range s.Values {
+			x, y := point(j, value)
+			fmt.Fprintf(&b,
+				`<circle cx="%d" cy="%d" r="3" fill="%s"><title>%s, %s: %.3f</title></circle>`,
+				x, y, color, html.EscapeString(s.Name), html.EscapeString(labels[j]), value,
+			)
+		}
+	}
+
+	for i, label := range labels {
+		if labelEvery > 0 && i%labelEvery == 0 {
+			x, _ := point(i, 0)
+			fmt.Fprintf(&b,
+				`<text x="%d" y="%d" text-anchor="middle">%s</text>`,
+				x, chartHeight-6, html.EscapeString(label),
+			)
+		}
+	}
+
+	if high > low {
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%.2f</text>`, chartPadding, valueHeight-3, high)
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func openSVG(b *strings.Builder, title string, width int) {
+	fmt.Fprintf(b,
+		`<svg class="chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s"><title>%s</title>`,
+		width, chartHeight, width, chartHeight, html.EscapeString(title), html.EscapeString(title),
+	)
+}
+
+func maxOf(values []int) int {
+	result := 0
+	for _, value := range values {
+		result = max(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"sort"
+	"spire/calendar"
+	"spire/entry"
+	"strings"
+	"time"
+)
+
+// GetDailyActivity counts entries and words per calendar day in loc. Days
+// without entries are left out.
+func (s *SQLiteStorage) GetDailyActivity(loc *time.Location) ([]calendar.Day, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// Grouping happens here rather than in SQL, because SQLite only knows
+	// fixed offsets and days need to follow the location's DST rules.
+	rows, err := db.Query("SELECT id, time, content FROM entries WHERE deleted_at IS NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDay := map[time.Time]*calendar.Day{}
+
+	for rows.Next() {
+		var id int64
+		var timeString string
+		var content string
+		err := rows.Scan(&id, &timeString, &content)
+		if err != nil {
+			return nil, err
+		}
+
+		written, err := parseTimestamp(timeString)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err = s.openContent(entryContent, id, content)
+		if err != nil {
+			return nil, err
+		}
+
+		year, month, day := written.In(loc).Date()
+		date := startOfDay(year, month, day, loc)
+
+		activity, ok := byDay[date]
+		if !ok {
+			activity = &calendar.Day{Date: date}
+			byDay[date] = activity
+		}
+
+		activity.Entries++
+		activity.Words += len(strings.Fields(content))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]calendar.Day, 0, len(byDay))
+	for _, activity := range byDay {
+		result = append(result, *activity)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Date.Before(result[j].Date)
+	})
+
+	return result, nil
+}
+
+// GetEntryTexts returns every entry with only its ID, time, content and
+// whether it's private, for analyses that don't need embeddings, tags or
+// links.
+func (s *SQLiteStorage) GetEntryTexts() ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, time, content, private FROM entries WHERE deleted_at IS NULL ORDER BY time")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entry.Entry
+
+	for rows.Next() {
+		var e entry.Entry
+		var timeString string
+		err := rows.Scan(&e.ID, &timeString, &e.Content, &e.Private)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Time, err = parseTimestamp(timeString)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Content, err = s.openContent(entryContent, e.ID, e.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"os"
+	"spire/calendar"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestDailyActivity(t *testing.T) {
+	testDatabasePath := "activity_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embedding := generateRandomEmbeddings()
+	for _, e := range []entry.Entry{
+		{Time: time.Date(2025, 3, 9, 8, 0, 0, 0, newYork), Content: "one two three"},
+		// Still March 9th in New York.
+		{Time: time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC), Content: "four five"},
+		{Time: time.Date(2025, 3, 11, 8, 0, 0, 0, newYork), Content: "six"},
+	} {
+		e.Embedding = embedding
+		_, err := store.SaveEntry(e)
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+	}
+
+	activity, err := store.GetDailyActivity(newYork)
+	if err != nil {
+		t.Fatalf("error getting activity: %v\n", err)
+	}
+
+	expected := []calendar.Day{
+		{Date: time.Date(2025, 3, 9, 0, 0, 0, 0, newYork), Entries: 2, Words: 5},
+		{Date: time.Date(2025, 3, 11, 0, 0, 0, 0, newYork), Entries: 1, Words: 1},
+	}
+
+	if len(activity) != len(expected) {
+		t.Fatalf("expected %d days, got %v", len(expected), activity)
+	}
+
+	for i := range expected {
+		if !activity[i].Date.Equal(expected[i].Date) || activity[i].Entries != expected[i].Entries || activity[i].Words != expected[i].Words {
+			t.Errorf("expected %v, got %v", expected[i], activity[i])
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"spire/entry"
+)
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// The attachment columns loadAttachments and GetAttachment scan, in order.
+const attachmentColumns = "id, name, content_type, size, hash, thumbnail_hash"
+
+func insertAttachments(tx *sql.Tx, entryID int64, attachments []entry.Attachment) error {
+	for _, a := range attachments {
+		thumbnail := sql.NullString{String: a.ThumbnailHash, Valid: a.ThumbnailHash != ""}
+
+		_, err := tx.Exec(`
+			INSERT INTO attachments (entry_id, name, content_type, size, hash, thumbnail_hash)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, entryID, a.Name, a.ContentType, a.Size, a.Hash, thumbnail)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadAttachments(db *sql.DB, entries []entry.Entry) error {
+	return queryByEntryIDs(db, entries,
+		"SELECT entry_id, "+attachmentColumns+" FROM attachments WHERE entry_id IN (%s) ORDER BY id",
+		func(rows *sql.Rows, byID map[int64]*entry.Entry) error {
+			var entryID int64
+			var a entry.Attachment
+			var thumbnail sql.NullString
+			err := rows.Scan(&entryID, &a.ID, &a.Name, &a.ContentType, &a.Size, &a.Hash, &thumbnail)
+			if err != nil {
+				return err
+			}
+
+			a.ThumbnailHash = thumbnail.String
+
+			e := byID[entryID]
+			e.Attachments = append(e.Attachments, a)
+			return nil
+		},
+	)
+}
+
+// GetAttachment returns one of an entry's attachments. Attachments of entries
+// in the trash, or asked for under the wrong entry, aren't found.
+func (s *SQLiteStorage) GetAttachment(entryID int64, id int64) (entry.Attachment, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return entry.Attachment{}, err
+	}
+	defer db.Close()
+
+	var a entry.Attachment
+	var thumbnail sql.NullString
+
+	err = db.QueryRow(`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE id = ?
+			AND entry_id = ?
+			AND entry_id IN (SELECT id FROM entries WHERE deleted_at IS NULL)
+	`, id, entryID).Scan(&a.ID, &a.Name, &a.ContentType, &a.Size, &a.Hash, &thumbnail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entry.Attachment{}, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return entry.Attachment{}, err
+	}
+
+	a.ThumbnailHash = thumbnail.String
+	return a, nil
+}
+
+// GetAttachmentHashes returns the hash of every stored file and thumbnail
+// that an attachment still refers to, including those of entries in the
+// trash.
+func (s *SQLiteStorage) GetAttachmentHashes() (map[string]bool, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT hash FROM attachments
+		UNION
+		SELECT thumbnail_hash FROM attachments WHERE thumbnail_hash IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := map[string]bool{}
+
+	for rows.Next() {
+		var hash string
+		err := rows.Scan(&hash)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes[hash] = true
+	}
+
+	return hashes, rows.Err()
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestAttachments(t *testing.T) {
+	testDatabasePath := "attachments_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	photo := entry.Attachment{Name: "garden.jpg", ContentType: "image/jpeg", Size: 2048, Hash: "aa", ThumbnailHash: "bb"}
+	notes := entry.Attachment{Name: "notes.pdf", ContentType: "application/pdf", Size: 4096, Hash: "cc"}
+
+	id, err := store.SaveEntry(entry.Entry{
+		Time:        time.Now(),
+		Content:     "planted tomatoes",
+		Embedding:   generateRandomEmbeddings(),
+		Attachments: []entry.Attachment{photo, notes},
+	})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	e, err := store.GetEntry(id)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	if len(e.Attachments) != 2 || e.Attachments[0].Name != "garden.jpg" || e.Attachments[1].ThumbnailHash != "" {
+		t.Fatalf("expected both attachments in order, got %v", e.Attachments)
+	}
+
+	a, err := store.GetAttachment(id, e.Attachments[0].ID)
+	if err != nil || a.ThumbnailHash != "bb" || !a.IsImage() {
+		t.Errorf("expected the photo, got %v (%v)", a, err)
+	}
+
+	_, err = store.GetAttachment(id+1, e.Attachments[0].ID)
+	if !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("expected ErrAttachmentNotFound under another entry, got %v", err)
+	}
+
+	hashes, err := store.GetAttachmentHashes()
+	if err != nil || len(hashes) != 3 || !hashes["bb"] {
+		t.Errorf("expected the files and the thumbnail, got %v (%v)", hashes, err)
+	}
+
+	err = store.DeleteEntry(id)
+	if err != nil {
+		t.Fatalf("error deleting entry: %v\n", err)
+	}
+
+	_, err = store.GetAttachment(id, e.Attachments[0].ID)
+	if !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("expected attachments of trashed entries to be hidden, got %v", err)
+	}
+
+	_, err = store.PurgeEntriesDeletedBefore(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("error purging: %v\n", err)
+	}
+
+	hashes, err = store.GetAttachmentHashes()
+	if err != nil || len(hashes) != 0 {
+		t.Errorf("expected no hashes after purging, got %v (%v)", hashes, err)
+	}
+}
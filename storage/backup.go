@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SchemaVersion is recorded in the database's user_version so a backup can
+// be checked before it's restored. Bump it when a change to the schema means
+// older code can't read the database, or would show it wrongly:
+//
+//   - 3: entry content can be sealed, which older code shows as ciphertext.
+//   - 4: entries can be private, which older code shows to anyone.
+//   - 5: entries are in notebooks, which older code mixes together.
+const SchemaVersion = 5
+
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// Backup writes a consistent copy of the database to path with VACUUM INTO,
+// which is safe while the server is writing. The copy is also compacted.
+// path must not exist yet.
+func (s *SQLiteStorage) Backup(path string) error {
+	db, err := s.getDatabaseConnection()
+	if s.replica != nil {
+		// A replica would send VACUUM to the primary, so the snapshot is
+		// taken from the local copy directly.
+		db, err = sql.Open("libsql", "file:"+s.databaseName)
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("VACUUM INTO ?", path)
+	return err
+}
+
+// libsql's vector indexes aren't regular b-tree indexes, so integrity_check
+// reports every indexed row as missing from them, and the count as wrong. It
+// can also complain about the order of the vector metadata table. None of
+// these mean anything is damaged.
+var vectorIndexMessage = regexp.MustCompile(
+	`^(row \d+ missing from index|wrong # of entries in index) (\S+)$|for libsql_vector_meta_shadow$`,
+)
+
+// CheckDatabase makes sure the database at path is one this version of Spire
+// can open, and that SQLite finds nothing wrong with it.
+func CheckDatabase(path string) error {
+	_, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("libsql", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var version int
+	err = db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		// Databases from before the version was recorded still have entries.
+		var count int
+		err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'entries'").Scan(&count)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return fmt.Errorf("%w: not a spire database", ErrSchemaVersion)
+		}
+	} else if version > SchemaVersion {
+		return fmt.Errorf("%w: %d is newer than this version of spire supports (%d)", ErrSchemaVersion, version, SchemaVersion)
+	}
+
+	vectorIndexes, err := getVectorIndexes(db)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+
+	for rows.Next() {
+		var message string
+		err := rows.Scan(&message)
+		if err != nil {
+			return err
+		}
+
+		if message == "ok" {
+			continue
+		}
+
+		match := vectorIndexMessage.FindStringSubmatch(message)
+		if match != nil && (match[2] == "" || vectorIndexes[match[2]]) {
+			continue
+		}
+
+		problems = append(problems, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+func getVectorIndexes(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND sql LIKE '%libsql_vector_idx%'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := map[string]bool{}
+
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes[name] = true
+	}
+
+	return indexes, rows.Err()
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	testDatabasePath := "backup_test.db"
+	backupPath := "backup_test_copy.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+	defer os.Remove(backupPath)
+
+	embedding := generateRandomEmbeddings()
+	for _, content := range []string{"first", "second", "third"} {
+		_, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+	}
+
+	err = store.Backup(backupPath)
+	if err != nil {
+		t.Fatalf("error backing up: %v\n", err)
+	}
+
+	// The vector index's false alarms shouldn't fail the check.
+	err = CheckDatabase(backupPath)
+	if err != nil {
+		t.Fatalf("expected the backup to pass the check, got %v", err)
+	}
+
+	backup, err := NewSQLiteStorage(backupPath)
+	if err != nil {
+		t.Fatalf("error opening backup: %v\n", err)
+	}
+
+	similar, err := backup.SearchEntriesEmbedding(embedding)
+	if err != nil {
+		t.Fatalf("error searching backup: %v\n", err)
+	}
+
+	if len(similar) != 3 {
+		t.Errorf("expected vector search to find all 3 entries in the backup, got %v", contents(similar))
+	}
+}
+
+func TestCheckDatabase(t *testing.T) {
+	testDatabasePath := "check_test.db"
+
+	_, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	db, err := sql.Open("libsql", "file:"+testDatabasePath)
+	if err != nil {
+		t.Fatalf("error opening database: %v\n", err)
+	}
+
+	_, err = db.Exec("PRAGMA user_version = 1000")
+	db.Close()
+	if err != nil {
+		t.Fatalf("error setting version: %v\n", err)
+	}
+
+	err = CheckDatabase(testDatabasePath)
+	if !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("expected ErrSchemaVersion for a newer schema, got %v", err)
+	}
+
+	err = os.WriteFile(testDatabasePath, []byte("not a database"), 0o644)
+	if err != nil {
+		t.Fatalf("error writing file: %v\n", err)
+	}
+
+	err = CheckDatabase(testDatabasePath)
+	if err == nil {
+		t.Errorf("expected an error for a file that isn't a database")
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"spire/entry"
+	"strings"
+)
+
+// Keeps the number of query parameters well under SQLite's limit.
+const entryBatchSize = 500
+
+// queryByEntryIDs runs a query for rows related to the given entries, in
+// batches. The query has a single %s for the list of IDs. scan is called for
+// each row, with a lookup from entry ID to the entry to fill in.
+func queryByEntryIDs(db *sql.DB, entries []entry.Entry, query string, scan func(rows *sql.Rows, byID map[int64]*entry.Entry) error) error {
+	byID := make(map[int64]*entry.Entry, len(entries))
+	for i := range entries {
+		byID[entries[i].ID] = &entries[i]
+	}
+
+	for start := 0; start < len(entries); start += entryBatchSize {
+		end := min(start+entryBatchSize, len(entries))
+
+		args := make([]any, 0, end-start)
+		for _, e := range entries[start:end] {
+			args = append(args, e.ID)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+		rows, err := db.Query(fmt.Sprintf(query, placeholders), args...)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			err = scan(rows, byID)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"fmt"
+	"spire/entry"
+	"time"
+)
+
+// How many of the nearest neighbors to consider when looking for a near
+// duplicate. Only the recent ones among them count.
+const duplicateCandidates = 10
+
+// GetNearestRecentEntry returns the entry written since the given time that's
+// most similar to the embedding, along with its cosine similarity. It returns
+// ErrEntryNotFound if no recent entry is among the nearest neighbors. Private
+// entries are skipped, since offering one to merge into would show it.
+func (s *SQLiteStorage) GetNearestRecentEntry(embedding entry.Vector, since time.Time) (entry.Entry, float64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return entry.Entry{}, 0, err
+	}
+	defer db.Close()
+
+	vector := entry.SerializeEmbeddingsWithVectorPrefix(embedding)
+	query := fmt.Sprintf(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id IN (SELECT id FROM vector_top_k('entries_idx', %s, ?))
+			AND unixepoch(time) >= ?
+			AND deleted_at IS NULL
+			AND private = 0
+		ORDER BY vector_distance_cos(embedding, %s)
+		LIMIT 1
+	`, vector, vector)
+
+	candidates, err := vectorCandidates(db, duplicateCandidates)
+	if err != nil {
+		return entry.Entry{}, 0, err
+	}
+
+	entries, err := s.queryEntries(db, query, candidates, since.Unix())
+	if err != nil {
+		return entry.Entry{}, 0, err
+	}
+
+	if len(entries) == 0 {
+		return entry.Entry{}, 0, ErrEntryNotFound
+	}
+
+	return entries[0], entries[0].Embedding.CosineSimilarity(embedding), nil
+}
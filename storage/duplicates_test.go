@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestNearestRecentEntry(t *testing.T) {
+	testDatabasePath := "duplicates_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	now := time.Now()
+	base := generateRandomEmbeddings()
+
+	// The same note pasted a year ago is too old to count.
+	_, err = store.SaveEntry(entry.Entry{Time: now.AddDate(-1, 0, 0), Content: "old copy", Embedding: base})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	// A private copy doesn't count either.
+	_, err = store.SaveEntry(entry.Entry{Time: now, Content: "private copy", Embedding: base, Private: true})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	_, _, err = store.GetNearestRecentEntry(base, now.AddDate(0, 0, -30))
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound with only old and private entries, got %v", err)
+	}
+
+	for content, embedding := range map[string]entry.Vector{
+		"recent copy": base,
+		"greeting":    greetingEmbeddings,
+	} {
+		_, err = store.SaveEntry(entry.Entry{Time: now, Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+	}
+
+	nearest, similarity, err := store.GetNearestRecentEntry(base, now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("error getting nearest entry: %v\n", err)
+	}
+
+	if nearest.Content != "recent copy" || similarity < 0.999 {
+		t.Errorf("expected the recent copy with a similarity of 1, got %q at %v", nearest.Content, similarity)
+	}
+}
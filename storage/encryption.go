@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"spire/seal"
+)
+
+// When a journal is encrypted, the content of entries, revisions and queued
+// imports is sealed before it's written and opened as it's read, so nothing
+// outside this file has to know. Everything else, including embeddings, tags
+// and link labels, is stored as before.
+
+// The seal params the journal's key was derived with, as JSON. Only
+// encrypted journals have it.
+const encryptionSetting = "encryption"
+
+var (
+	ErrNotEncrypted     = errors.New("the journal isn't encrypted")
+	ErrAlreadyEncrypted = errors.New("the journal is already encrypted")
+	// Returned when reading sealed content without having unlocked the
+	// journal.
+	ErrLocked = errors.New("the journal is encrypted and hasn't been unlocked")
+)
+
+// A sealedColumn holds entry text. Each value in it is sealed with a label
+// naming the column and its row, so a value copied to another row, or another
+// table, won't open there.
+type sealedColumn struct{ table, column string }
+
+var (
+	entryContent    = sealedColumn{"entries", "content"}
+	revisionContent = sealedColumn{"entry_revisions", "content"}
+	pendingContent  = sealedColumn{"pending_entries", "content"}
+)
+
+var sealedColumns = []sealedColumn{entryContent, revisionContent, pendingContent}
+
+// "entries.content:12"
+func (c sealedColumn) label(id int64) string {
+	return fmt.Sprintf("%s.%s:%d", c.table, c.column, id)
+}
+
+// Encrypted reports whether the journal has been encrypted, whether or not
+// it's unlocked.
+func (s *SQLiteStorage) Encrypted() (bool, error) {
+	_, ok, err := s.GetSetting(encryptionSetting)
+	return ok, err
+}
+
+// Unlock derives the journal's key from its passphrase. Until it's called,
+// reading an encrypted journal's entries fails with ErrLocked.
+func (s *SQLiteStorage) Unlock(passphrase string) error {
+	params, err := s.encryptionParams()
+	if err != nil {
+		return err
+	}
+
+	key, err := seal.Unlock(passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	s.key = key
+	return nil
+}
+
+// Encrypt seals the content of every entry, including those in the trash,
+// with a key derived from the passphrase, and leaves the journal unlocked.
+func (s *SQLiteStorage) Encrypt(passphrase string) error {
+	encrypted, err := s.Encrypted()
+	if err != nil {
+		return err
+	}
+
+	if encrypted {
+		return ErrAlreadyEncrypted
+	}
+
+	key, params, err := seal.NewKey(passphrase)
+	if err != nil {
+		return err
+	}
+
+	err = s.reseal(params, func(label string, text string) (string, error) {
+		if seal.IsSealed(text) {
+			return "", errors.New("found sealed content in a journal that isn't encrypted")
+		}
+
+		return key.Seal(text, label)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.key = key
+	return nil
+}
+
+// Rekey seals everything again with a new key, derived from a new passphrase
+// and a fresh salt, and leaves the journal unlocked with it. The new
+// passphrase can be the same as the old one to rotate only the key.
+func (s *SQLiteStorage) Rekey(passphrase string, newPassphrase string) error {
+	params, err := s.encryptionParams()
+	if err != nil {
+		return err
+	}
+
+	oldKey, err := seal.Unlock(passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	newKey, newParams, err := seal.NewKey(newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	err = s.reseal(newParams, func(label string, text string) (string, error) {
+		// A replica that hadn't been given the passphrase yet may have
+		// written plaintext since the journal was encrypted.
+		if !seal.IsSealed(text) {
+			return newKey.Seal(text, label)
+		}
+
+		plaintext, err := oldKey.Open(text, label)
+		if err != nil {
+			return "", err
+		}
+
+		return newKey.Seal(plaintext, label)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.key = newKey
+	return nil
+}
+
+func (s *SQLiteStorage) encryptionParams() (seal.Params, error) {
+	value, ok, err := s.GetSetting(encryptionSetting)
+	if err != nil {
+		return seal.Params{}, err
+	}
+
+	if !ok {
+		return seal.Params{}, ErrNotEncrypted
+	}
+
+	var params seal.Params
+	err = json.Unmarshal([]byte(value), &params)
+	return params, err
+}
+
+// reseal rewrites all entry text with transform, which is given each value's
+// label, and stores the params of the key it was sealed with, in one
+// transaction so the journal is never left with text sealed under two keys.
+func (s *SQLiteStorage) reseal(params seal.Params, transform func(label string, text string) (string, error)) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sealed := range sealedColumns {
+		texts, err := loadColumn(tx, sealed.table, sealed.column)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", sealed.table, sealed.column)
+		for id, text := range texts {
+			text, err = transform(sealed.label(id), text)
+			if err != nil {
+				return fmt.Errorf("%s %d: %w", sealed.table, id, err)
+			}
+
+			_, err = tx.Exec(query, text, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	value, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	err = setSetting(tx, encryptionSetting, string(value))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadColumn maps each row's ID to a text column. Reading it all before
+// writing any keeps the updates from running into the open query.
+func loadColumn(tx *sql.Tx, table string, column string) (map[int64]string, error) {
+	rows, err := tx.Query(fmt.Sprintf("SELECT id, %s FROM %s", column, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := map[int64]string{}
+
+	for rows.Next() {
+		var id int64
+		var text string
+		err := rows.Scan(&id, &text)
+		if err != nil {
+			return nil, err
+		}
+
+		texts[id] = text
+	}
+
+	return texts, rows.Err()
+}
+
+// sealContent encrypts entry text on its way into a row of the database, if
+// the journal is encrypted.
+func (s *SQLiteStorage) sealContent(c sealedColumn, id int64, text string) (string, error) {
+	if s.key == nil {
+		return text, nil
+	}
+
+	return s.key.Seal(text, c.label(id))
+}
+
+// openContent decrypts entry text read from a row of the database. Text that
+// isn't sealed is returned as it is.
+func (s *SQLiteStorage) openContent(c sealedColumn, id int64, text string) (string, error) {
+	if !seal.IsSealed(text) {
+		return text, nil
+	}
+
+	if s.key == nil {
+		return "", ErrLocked
+	}
+
+	return s.key.Open(text, c.label(id))
+}
+
+// writeContent seals text for a row and stores it. A new row has no ID to
+// seal with until it's inserted, so it's inserted with empty content and
+// given its text here.
+func (s *SQLiteStorage) writeContent(tx *sql.Tx, c sealedColumn, id int64, text string) error {
+	text, err := s.sealContent(c, id, text)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", c.table, c.column), text, id)
+	return err
+}
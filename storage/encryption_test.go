@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"spire/seal"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncryption(t *testing.T) {
+	testDatabasePath := "encryption_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+
+	first, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "# Morning\nfirst draft", Embedding: embedding})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	err = store.UpdateEntry(entry.Entry{ID: first, Content: "# Morning\nwalked the Dog", Embedding: embedding})
+	if err != nil {
+		t.Fatalf("error updating entry: %v\n", err)
+	}
+
+	trashed, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "regrets", Embedding: generateRandomEmbeddings()})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	err = store.DeleteEntry(trashed)
+	if err != nil {
+		t.Fatalf("error deleting entry: %v\n", err)
+	}
+
+	err = store.QueueEntries([]entry.Entry{{Time: time.Now(), Content: "imported"}})
+	if err != nil {
+		t.Fatalf("error queueing entry: %v\n", err)
+	}
+
+	err = store.Unlock("hunter2")
+	if !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted before encrypting, got %v\n", err)
+	}
+
+	err = store.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("error encrypting: %v\n", err)
+	}
+
+	err = store.Encrypt("hunter2")
+	if !errors.Is(err, ErrAlreadyEncrypted) {
+		t.Errorf("expected ErrAlreadyEncrypted, got %v\n", err)
+	}
+
+	for _, sealed := range sealedColumns {
+		assertAllSealed(t, store, sealed.table, sealed.column)
+	}
+
+	// Entries written after encrypting are sealed too.
+	second, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "links to [[Morning]]", Embedding: generateRandomEmbeddings()})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	assertAllSealed(t, store, "entries", "content")
+
+	// A new storage, as on the next start, can't read anything until it's
+	// unlocked.
+	reopened, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error opening storage: %v\n", err)
+	}
+
+	_, err = reopened.GetEntry(first)
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked before unlocking, got %v\n", err)
+	}
+
+	err = reopened.Unlock("hunter3")
+	if !errors.Is(err, seal.ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v\n", err)
+	}
+
+	err = reopened.Unlock("hunter2")
+	if err != nil {
+		t.Fatalf("error unlocking: %v\n", err)
+	}
+
+	assertReadable(t, reopened, first, second)
+
+	err = reopened.Rekey("hunter3", "correct horse")
+	if !errors.Is(err, seal.ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase rekeying with the wrong passphrase, got %v\n", err)
+	}
+
+	err = reopened.Rekey("hunter2", "correct horse")
+	if err != nil {
+		t.Fatalf("error rekeying: %v\n", err)
+	}
+
+	err = store.Unlock("hunter2")
+	if !errors.Is(err, seal.ErrWrongPassphrase) {
+		t.Errorf("expected the old passphrase to stop working, got %v\n", err)
+	}
+
+	err = store.Unlock("correct horse")
+	if err != nil {
+		t.Fatalf("error unlocking with the new passphrase: %v\n", err)
+	}
+
+	assertReadable(t, store, first, second)
+
+	err = store.RestoreEntry(trashed)
+	if err != nil {
+		t.Fatalf("error restoring entry: %v\n", err)
+	}
+
+	restored, err := store.GetEntry(trashed)
+	if err != nil || restored.Content != "regrets" {
+		t.Errorf("expected the trashed entry to survive rekeying, got %q (%v)\n", restored.Content, err)
+	}
+
+	// Rewriting every row mustn't upset the vector index.
+	err = CheckDatabase(testDatabasePath)
+	if err != nil {
+		t.Errorf("expected the database to pass its checks, got %v\n", err)
+	}
+}
+
+func TestSwappedSealedContent(t *testing.T) {
+	testDatabasePath := "swapped_sealed_content_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	err = store.Encrypt("hunter2")
+	if err != nil {
+		t.Fatalf("error encrypting: %v\n", err)
+	}
+
+	ids, err := store.SaveEntries([]entry.Entry{
+		{Time: time.Now(), Content: "meant for everyone", Embedding: generateRandomEmbeddings()},
+		{Time: time.Now(), Content: "meant for nobody", Embedding: generateRandomEmbeddings()},
+	})
+	if err != nil {
+		t.Fatalf("error saving entries: %v\n", err)
+	}
+
+	db, err := store.getDatabaseConnection()
+	if err != nil {
+		t.Fatalf("error connecting: %v\n", err)
+	}
+	defer db.Close()
+
+	// Someone with access to the database file, but not the passphrase,
+	// moves one entry's sealed text into the other.
+	_, err = db.Exec("UPDATE entries SET content = (SELECT content FROM entries WHERE id = ?) WHERE id = ?", ids[1], ids[0])
+	if err != nil {
+		t.Fatalf("error swapping content: %v\n", err)
+	}
+
+	_, err = store.GetEntry(ids[0])
+	if !errors.Is(err, seal.ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt for content sealed for another row, got %v\n", err)
+	}
+
+	e, err := store.GetEntry(ids[1])
+	if err != nil || e.Content != "meant for nobody" {
+		t.Errorf("expected the other entry to be untouched, got %q (%v)\n", e.Content, err)
+	}
+}
+
+func assertAllSealed(t *testing.T, store *SQLiteStorage, table string, column string) {
+	t.Helper()
+
+	db, err := store.getDatabaseConnection()
+	if err != nil {
+		t.Fatalf("error connecting: %v\n", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error starting transaction: %v\n", err)
+	}
+	defer tx.Rollback()
+
+	texts, err := loadColumn(tx, table, column)
+	if err != nil {
+		t.Fatalf("error reading %s: %v\n", table, err)
+	}
+
+	if len(texts) == 0 {
+		t.Fatalf("expected rows in %s\n", table)
+	}
+
+	for id, text := range texts {
+		if !seal.IsSealed(text) {
+			t.Errorf("expected %s %d to be sealed, got %q\n", table, id, text)
+		}
+	}
+}
+
+func assertReadable(t *testing.T, store *SQLiteStorage, first int64, second int64) {
+	t.Helper()
+
+	e, err := store.GetEntry(first)
+	if err != nil || e.Content != "# Morning\nwalked the Dog" {
+		t.Fatalf("expected the entry's content, got %q (%v)\n", e.Content, err)
+	}
+
+	revisions, err := store.GetRevisions(first)
+	if err != nil || len(revisions) != 1 || revisions[0].Content != "# Morning\nfirst draft" {
+		t.Errorf("expected the earlier version, got %v (%v)\n", revisions, err)
+	}
+
+	pending, err := store.GetPendingEntries(0)
+	if err != nil || len(pending) != 1 || pending[0].Content != "imported" {
+		t.Errorf("expected the queued entry, got %v (%v)\n", pending, err)
+	}
+
+	// The database can't search sealed text, so this is done in Go, still
+	// ignoring case.
+	found, err := store.SearchEntries("dog")
+	if err != nil || len(found) != 1 || found[0].ID != first {
+		t.Errorf("expected search to find the entry, got %v (%v)\n", found, err)
+	}
+
+	linking, err := store.GetEntry(second)
+	if err != nil || len(linking.Links) != 1 || linking.Links[0].TargetID != first {
+		t.Errorf("expected the link to resolve by title, got %v (%v)\n", linking.Links, err)
+	}
+
+	similar, err := store.SearchEntriesEmbedding(e.Embedding)
+	if err != nil || len(similar) == 0 || similar[0].ID != first {
+		t.Errorf("expected vector search to keep working, got %v (%v)\n", similar, err)
+	}
+
+	if strings.Contains(strings.Join(contents(similar), ""), seal.Prefix) {
+		t.Errorf("expected opened content from vector search\n")
+	}
+}
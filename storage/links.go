@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"database/sql"
+	"spire/entry"
+)
+
+// replaceLinks stores the [[links]] in an entry's content, resolving each
+// label to an entry ID where possible. Labels that don't match anything are
+// kept, so they can be resolved once a matching entry exists.
+func (s *SQLiteStorage) replaceLinks(tx *sql.Tx, sourceID int64, content string) error {
+	_, err := tx.Exec("DELETE FROM entry_links WHERE source_id = ?", sourceID)
+	if err != nil {
+		return err
+	}
+
+	labels := entry.ExtractLinks(content)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	// Only loaded if some link needs to be matched by title.
+	var titles map[string]int64
+
+	for _, label := range labels {
+		var targetID sql.NullInt64
+
+		if id, ok := entry.LinkID(label); ok {
+			var exists bool
+			err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM entries WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists)
+			if err != nil {
+				return err
+			}
+
+			targetID = sql.NullInt64{Int64: id, Valid: exists}
+		} else {
+			if titles == nil {
+				titles, err = s.loadTitles(tx)
+				if err != nil {
+					return err
+				}
+			}
+
+			id, ok := titles[entry.TitleKey(label)]
+			targetID = sql.NullInt64{Int64: id, Valid: ok}
+		}
+
+		if targetID.Int64 == sourceID {
+			targetID = sql.NullInt64{}
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO entry_links (source_id, label, target_id) VALUES (?, ?, ?)",
+			sourceID, label, targetID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadTitles maps title keys to entry IDs. When titles collide, the most
+// recent entry wins.
+func (s *SQLiteStorage) loadTitles(tx *sql.Tx) (map[string]int64, error) {
+	rows, err := tx.Query("SELECT id, content FROM entries WHERE deleted_at IS NULL ORDER BY time")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := map[string]int64{}
+
+	for rows.Next() {
+		var id int64
+		var content string
+		err := rows.Scan(&id, &content)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err = s.openContent(entryContent, id, content)
+		if err != nil {
+			return nil, err
+		}
+
+		title := entry.TitleKey(entry.Title(content))
+		if title != "" {
+			titles[title] = id
+		}
+	}
+
+	return titles, rows.Err()
+}
+
+// resolveDanglingLinks points links that didn't match anything yet at a newly
+// saved entry, if they name it. Labels are compared in Go, the same way
+// replaceLinks compares them, since SQLite's lower() only folds ASCII.
+func resolveDanglingLinks(tx *sql.Tx, id int64, content string) error {
+	rows, err := tx.Query("SELECT source_id, label FROM entry_links WHERE target_id IS NULL AND source_id != ?", id)
+	if err != nil {
+		return err
+	}
+
+	type danglingLink struct {
+		sourceID int64
+		label    string
+	}
+
+	title := entry.TitleKey(entry.Title(content))
+
+	var matches []danglingLink
+	for rows.Next() {
+		var link danglingLink
+		err := rows.Scan(&link.sourceID, &link.label)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		linkID, isID := entry.LinkID(link.label)
+		if (isID && linkID == id) || (!isID && title != "" && entry.TitleKey(link.label) == title) {
+			matches = append(matches, link)
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, link := range matches {
+		_, err = tx.Exec(
+			"UPDATE entry_links SET target_id = ? WHERE source_id = ? AND label = ? AND target_id IS NULL",
+			id, link.sourceID, link.label,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadLinks(db *sql.DB, entries []entry.Entry) error {
+	return queryByEntryIDs(db, entries,
+		"SELECT source_id, label, target_id FROM entry_links WHERE source_id IN (%s) ORDER BY label",
+		func(rows *sql.Rows, byID map[int64]*entry.Entry) error {
+			var sourceID int64
+			var link entry.Link
+			var targetID sql.NullInt64
+			err := rows.Scan(&sourceID, &link.Label, &targetID)
+			if err != nil {
+				return err
+			}
+
+			link.TargetID = targetID.Int64
+
+			e := byID[sourceID]
+			e.Links = append(e.Links, link)
+			return nil
+		},
+	)
+}
+
+// GetBacklinks returns the entries that link to the given entry.
+func (s *SQLiteStorage) GetBacklinks(id int64) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id IN (SELECT source_id FROM entry_links WHERE target_id = ?)
+			AND deleted_at IS NULL
+		ORDER BY time DESC
+	`, id)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"os"
+	"spire/entry"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLinks(t *testing.T) {
+	testDatabasePath := "links_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+	save := func(content string) int64 {
+		id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+
+		return id
+	}
+
+	morningID := save("# Morning pages\nwrote before work")
+	linkingID := save("following up on [[morning PAGES]] and [[" + strconv.FormatInt(morningID, 10) + "]], see [[Evening pages]] and [[émile]]")
+
+	linking, err := store.GetEntry(linkingID)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	targets := map[string]int64{}
+	for _, link := range linking.Links {
+		targets[link.Label] = link.TargetID
+	}
+
+	if targets["morning PAGES"] != morningID || targets[strconv.FormatInt(morningID, 10)] != morningID {
+		t.Errorf("expected title and ID links to resolve to %d, got %v", morningID, targets)
+	}
+
+	if target, ok := targets["Evening pages"]; !ok || target != 0 {
+		t.Errorf("expected a dangling link to Evening pages, got %v", targets)
+	}
+
+	// Creating the missing entries resolves the dangling links, with case
+	// folded beyond ASCII.
+	eveningID := save("Evening pages\nwrote after work")
+	emileID := save("# Émile\nmet for coffee")
+
+	linking, err = store.GetEntry(linkingID)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	for _, link := range linking.Links {
+		if link.Label == "Evening pages" && link.TargetID != eveningID {
+			t.Errorf("expected Evening pages to resolve to %d, got %d", eveningID, link.TargetID)
+		}
+
+		if link.Label == "émile" && link.TargetID != emileID {
+			t.Errorf("expected émile to resolve to %d, got %d", emileID, link.TargetID)
+		}
+	}
+
+	backlinks, err := store.GetBacklinks(morningID)
+	if err != nil {
+		t.Fatalf("error getting backlinks: %v\n", err)
+	}
+
+	if len(backlinks) != 1 || backlinks[0].ID != linkingID {
+		t.Errorf("expected entry %d to be the only backlink, got %v", linkingID, backlinks)
+	}
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"spire/entry"
+	"strings"
+)
+
+// A Notebook is a separate journal within the same database, like one for
+// work and one for dreams.
+type Notebook struct {
+	ID   int64
+	Name string
+	// How many entries are in it, not counting the trash.
+	Count int
+}
+
+// The notebook entries go in when nothing else is said, including imports.
+const (
+	DefaultNotebookID   = 1
+	defaultNotebookName = "Journal"
+)
+
+var (
+	ErrNotebookNotFound = errors.New("notebook not found")
+	ErrNotebookExists   = errors.New("there's already a notebook with that name")
+)
+
+// GetNotebooks returns every notebook in the order they were made, so the
+// default one comes first.
+func (s *SQLiteStorage) GetNotebooks() ([]Notebook, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT notebooks.id, notebooks.name, COUNT(entries.id)
+		FROM notebooks
+		LEFT JOIN entries ON entries.notebook_id = notebooks.id AND entries.deleted_at IS NULL
+		GROUP BY notebooks.id
+		ORDER BY notebooks.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notebooks []Notebook
+
+	for rows.Next() {
+		var notebook Notebook
+		err := rows.Scan(&notebook.ID, &notebook.Name, &notebook.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		notebooks = append(notebooks, notebook)
+	}
+
+	return notebooks, rows.Err()
+}
+
+func (s *SQLiteStorage) GetNotebook(id int64) (Notebook, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return Notebook{}, err
+	}
+	defer db.Close()
+
+	var notebook Notebook
+	err = db.QueryRow(`
+		SELECT id, name, (SELECT COUNT(*) FROM entries WHERE notebook_id = notebooks.id AND deleted_at IS NULL)
+		FROM notebooks
+		WHERE id = ?
+	`, id).Scan(&notebook.ID, &notebook.Name, &notebook.Count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Notebook{}, ErrNotebookNotFound
+	}
+
+	return notebook, err
+}
+
+// CreateNotebook adds an empty notebook and returns its ID. Names are unique,
+// ignoring case.
+func (s *SQLiteStorage) CreateNotebook(name string) (int64, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, errors.New("notebook name is required")
+	}
+
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM notebooks WHERE name = ?)", name).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+
+	if exists {
+		return 0, ErrNotebookExists
+	}
+
+	result, err := db.Exec("INSERT INTO notebooks (name) VALUES (?)", name)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// MoveEntry puts an entry in another notebook. Nothing about the entry
+// itself changes, so its embedding, topic and place on the map all stay.
+func (s *SQLiteStorage) MoveEntry(id int64, notebookID int64) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM notebooks WHERE id = ?)", notebookID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrNotebookNotFound
+	}
+
+	result, err := db.Exec("UPDATE entries SET notebook_id = ? WHERE id = ? AND deleted_at IS NULL", notebookID, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+
+	return nil
+}
+
+// GetNotebookEntries returns a notebook's entries, newest first.
+func (s *SQLiteStorage) GetNotebookEntries(notebookID int64) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE notebook_id = ? AND deleted_at IS NULL
+		ORDER BY time DESC
+	`, notebookID)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestNotebooks(t *testing.T) {
+	testDatabasePath := "notebooks_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	notebooks, err := store.GetNotebooks()
+	if err != nil || len(notebooks) != 1 || notebooks[0].ID != DefaultNotebookID {
+		t.Fatalf("expected only the default notebook, got %v (%v)", notebooks, err)
+	}
+
+	dreams, err := store.CreateNotebook(" Dreams ")
+	if err != nil {
+		t.Fatalf("error creating notebook: %v\n", err)
+	}
+
+	_, err = store.CreateNotebook("dreams")
+	if !errors.Is(err, ErrNotebookExists) {
+		t.Errorf("expected ErrNotebookExists for a name differing only in case, got %v", err)
+	}
+
+	embedding := generateRandomEmbeddings()
+
+	journalEntry, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "groceries", Embedding: embedding})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	dreamEntry, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "flying", Embedding: greetingEmbeddings, NotebookID: dreams})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	entries, err := store.GetNotebookEntries(dreams)
+	if err != nil || len(entries) != 1 || entries[0].ID != dreamEntry {
+		t.Fatalf("expected only the dream, got %v (%v)", contents(entries), err)
+	}
+
+	err = store.MoveEntry(journalEntry, dreams)
+	if err != nil {
+		t.Fatalf("error moving entry: %v\n", err)
+	}
+
+	moved, err := store.GetEntry(journalEntry)
+	if err != nil || moved.NotebookID != dreams {
+		t.Fatalf("expected the entry in the new notebook, got %v (%v)", moved, err)
+	}
+
+	// The embedding comes along rather than being computed again.
+	if moved.Embedding.CosineSimilarity(embedding) < 0.999 {
+		t.Errorf("expected the embedding to be kept")
+	}
+
+	notebook, err := store.GetNotebook(dreams)
+	if err != nil || notebook.Name != "Dreams" || notebook.Count != 2 {
+		t.Errorf("expected Dreams with two entries, got %v (%v)", notebook, err)
+	}
+
+	entries, err = store.GetNotebookEntries(DefaultNotebookID)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected the default notebook to be empty, got %v (%v)", contents(entries), err)
+	}
+
+	err = store.MoveEntry(journalEntry, dreams+1)
+	if !errors.Is(err, ErrNotebookNotFound) {
+		t.Errorf("expected ErrNotebookNotFound, got %v", err)
+	}
+
+	err = store.MoveEntry(dreamEntry+1, DefaultNotebookID)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	_, err = store.GetNotebook(dreams + 1)
+	if !errors.Is(err, ErrNotebookNotFound) {
+		t.Errorf("expected ErrNotebookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"spire/entry"
+	"strings"
+	"time"
+)
+
+// Timestamps are stored as text with whatever offset they were written with,
+// so ranges are compared as Unix times rather than as strings. unixepoch
+// rounds down to the second, which keeps half-open ranges exact.
+
+// GetEntriesBetween returns the entries written in [start, end).
+func (s *SQLiteStorage) GetEntriesBetween(start time.Time, end time.Time) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE unixepoch(time) >= ? AND unixepoch(time) < ? AND deleted_at IS NULL
+		ORDER BY unixepoch(time) DESC
+	`, start.Unix(), end.Unix())
+}
+
+// GetEntriesOnThisDay returns entries written on the same month and day as
+// now in earlier years, newest first. Days are calendar days in now's
+// location, so an entry written late in the evening lands on the day the
+// writer saw, not the day it was in UTC.
+func (s *SQLiteStorage) GetEntriesOnThisDay(now time.Time) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var earliest sql.NullInt64
+	err = db.QueryRow("SELECT MIN(unixepoch(time)) FROM entries WHERE deleted_at IS NULL").Scan(&earliest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !earliest.Valid {
+		return nil, nil
+	}
+
+	ranges := sameDayInPreviousYears(now, time.Unix(earliest.Int64, 0).In(now.Location()).Year())
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	var conditions []string
+	var args []any
+	for _, r := range ranges {
+		conditions = append(conditions, "(unixepoch(time) >= ? AND unixepoch(time) < ?)")
+		args = append(args, r.start.Unix(), r.end.Unix())
+	}
+
+	return s.queryEntries(db, fmt.Sprintf(`
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE (%s) AND deleted_at IS NULL
+		ORDER BY unixepoch(time) DESC
+	`, strings.Join(conditions, " OR ")), args...)
+}
+
+// GetEntriesSameWeekLastMonth returns entries from the Monday-to-Sunday week
+// that contained this day last month, along with the week's bounds.
+func (s *SQLiteStorage) GetEntriesSameWeekLastMonth(now time.Time) ([]entry.Entry, time.Time, time.Time, error) {
+	week := sameWeekLastMonth(now)
+
+	entries, err := s.GetEntriesBetween(week.start, week.end)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	return entries, week.start, week.end, nil
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// startOfDay uses time.Date rather than truncating, since a day isn't always
+// 24 hours long around DST changes.
+func startOfDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func sameDayInPreviousYears(now time.Time, earliestYear int) []timeRange {
+	var ranges []timeRange
+
+	for year := now.Year() - 1; year >= earliestYear; year-- {
+		start := startOfDay(year, now.Month(), now.Day(), now.Location())
+
+		// February 29th only comes around in leap years.
+		if start.Month() != now.Month() {
+			continue
+		}
+
+		ranges = append(ranges, timeRange{start: start, end: start.AddDate(0, 0, 1)})
+	}
+
+	return ranges
+}
+
+func sameWeekLastMonth(now time.Time) timeRange {
+	year, month, day := now.Date()
+
+	// Clamp, so March 31st maps to the end of February rather than
+	// overflowing into March.
+	firstOfLastMonth := startOfDay(year, month-1, 1, now.Location())
+	lastDay := firstOfLastMonth.AddDate(0, 1, -1).Day()
+	lastMonth := startOfDay(year, month-1, min(day, lastDay), now.Location())
+
+	daysSinceMonday := (int(lastMonth.Weekday()) + 6) % 7
+	start := lastMonth.AddDate(0, 0, -daysSinceMonday)
+
+	return timeRange{start: start, end: start.AddDate(0, 0, 7)}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestEntriesOnThisDay(t *testing.T) {
+	testDatabasePath := "onthisday_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	embedding := generateRandomEmbeddings()
+	for content, written := range map[string]time.Time{
+		// 22:30 on March 9th in New York, but already March 10th in UTC.
+		"late evening":  time.Date(2024, 3, 10, 3, 30, 0, 0, time.UTC),
+		"two years ago": time.Date(2023, 3, 9, 12, 0, 0, 0, newYork),
+		"day after":     time.Date(2023, 3, 10, 12, 0, 0, 0, newYork),
+		"this year":     time.Date(2025, 3, 9, 8, 0, 0, 0, newYork),
+		"last month":    time.Date(2025, 2, 4, 8, 0, 0, 0, newYork),
+	} {
+		_, err := store.SaveEntry(entry.Entry{Time: written, Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+	}
+
+	now := time.Date(2025, 3, 9, 18, 0, 0, 0, newYork)
+
+	entries, err := store.GetEntriesOnThisDay(now)
+	if err != nil {
+		t.Fatalf("error getting entries on this day: %v\n", err)
+	}
+
+	if len(entries) != 2 || entries[0].Content != "late evening" || entries[1].Content != "two years ago" {
+		t.Errorf("expected late evening and two years ago, got %v", contents(entries))
+	}
+
+	lastMonth, start, end, err := store.GetEntriesSameWeekLastMonth(now)
+	if err != nil {
+		t.Fatalf("error getting entries from last month: %v\n", err)
+	}
+
+	if len(lastMonth) != 1 || lastMonth[0].Content != "last month" {
+		t.Errorf("expected last month, got %v", contents(lastMonth))
+	}
+
+	expectedStart := time.Date(2025, 2, 3, 0, 0, 0, 0, newYork)
+	if !start.Equal(expectedStart) || !end.Equal(expectedStart.AddDate(0, 0, 7)) {
+		t.Errorf("expected the week of %v, got %v to %v", expectedStart, start, end)
+	}
+}
+
+func TestSameDayInPreviousYears(t *testing.T) {
+	leapDay := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+
+	ranges := sameDayInPreviousYears(leapDay, 2019)
+	if len(ranges) != 1 || ranges[0].start.Year() != 2020 {
+		t.Errorf("expected only 2020 to have a February 29th, got %v", ranges)
+	}
+}
+
+func TestSameWeekLastMonth(t *testing.T) {
+	// March 31st clamps to February 28th, a Friday.
+	week := sameWeekLastMonth(time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC))
+
+	expected := time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)
+	if !week.start.Equal(expected) {
+		t.Errorf("expected week to start %v, got %v", expected, week.start)
+	}
+}
+
+func contents(entries []entry.Entry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.Content
+	}
+
+	return result
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"spire/entry"
+	"strings"
+)
+
+// Imported entries wait in pending_entries until the embedding job has
+// embedded them, then move to entries. The vector index can't hold rows
+// without an embedding, so they can't wait in entries itself.
+
+// QueueEntries adds entries to be embedded in the background. Their
+// embeddings are ignored.
+func (s *SQLiteStorage) QueueEntries(entries []entry.Entry) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range entries {
+		result, err := tx.Exec(
+			"INSERT INTO pending_entries (time, content, tags, private) VALUES (?, ?, ?, ?)",
+			e.Time, "", strings.Join(e.Tags, ","), e.Private,
+		)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = s.writeContent(tx, pendingContent, id, e.Content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPendingEntries returns up to limit queued entries, oldest first. Their
+// IDs are queue IDs, not entry IDs. A limit of zero returns all of them.
+func (s *SQLiteStorage) GetPendingEntries(limit int) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := db.Query("SELECT id, time, content, tags, private FROM pending_entries ORDER BY time, id LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []entry.Entry
+
+	for rows.Next() {
+		var e entry.Entry
+		var timeString string
+		var tags string
+		err := rows.Scan(&e.ID, &timeString, &e.Content, &tags, &e.Private)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Time, err = parseTimestamp(timeString)
+		if err != nil {
+			return nil, err
+		}
+
+		e.Content, err = s.openContent(pendingContent, e.ID, e.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		if tags != "" {
+			e.Tags = strings.Split(tags, ",")
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *SQLiteStorage) CountPendingEntries() (int, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM pending_entries").Scan(&count)
+
+	return count, err
+}
+
+// PromotePendingEntries saves queued entries, now with embeddings, as real
+// entries and takes them off the queue, all in one transaction. It returns
+// the new entry IDs in order.
+func (s *SQLiteStorage) PromotePendingEntries(pending []entry.Entry) ([]int64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int64, len(pending))
+	for i, e := range pending {
+		_, err = tx.Exec("DELETE FROM pending_entries WHERE id = ?", e.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i], err = s.insertEntry(tx, e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, tx.Commit()
+}
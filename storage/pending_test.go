@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestPendingEntries(t *testing.T) {
+	testDatabasePath := "pending_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	now := time.Now()
+	err = store.QueueEntries([]entry.Entry{
+		{Time: now, Content: "newer #imported", Tags: []string{"starred", "travel"}},
+		{Time: now.AddDate(0, 0, -1), Content: "older", Private: true},
+	})
+	if err != nil {
+		t.Fatalf("error queueing entries: %v\n", err)
+	}
+
+	count, err := store.CountPendingEntries()
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 pending entries, got %d (%v)", count, err)
+	}
+
+	pending, err := store.GetPendingEntries(1)
+	if err != nil {
+		t.Fatalf("error getting pending entries: %v\n", err)
+	}
+
+	if len(pending) != 1 || pending[0].Content != "older" || !pending[0].Private {
+		t.Fatalf("expected the older, private entry first, got %v", contents(pending))
+	}
+
+	pending, err = store.GetPendingEntries(0)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("expected both pending entries, got %v (%v)", contents(pending), err)
+	}
+
+	for i := range pending {
+		pending[i].Embedding = generateRandomEmbeddings()
+	}
+
+	ids, err := store.PromotePendingEntries(pending)
+	if err != nil {
+		t.Fatalf("error promoting entries: %v\n", err)
+	}
+
+	count, err = store.CountPendingEntries()
+	if err != nil || count != 0 {
+		t.Errorf("expected the queue to be empty, got %d (%v)", count, err)
+	}
+
+	older, err := store.GetEntry(ids[0])
+	if err != nil || !older.Private {
+		t.Errorf("expected the older entry to stay private, got %v (%v)", older, err)
+	}
+
+	newer, err := store.GetEntry(ids[1])
+	if err != nil {
+		t.Fatalf("error getting promoted entry: %v\n", err)
+	}
+
+	if newer.Content != "newer #imported" || len(newer.Tags) != 3 {
+		t.Errorf("expected the newer entry with its queued and extracted tags, got %v", newer)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"encoding/json"
+	"spire/entry"
+	"spire/projection"
+)
+
+// The fitted model, stored so new entries can be placed without refitting.
+const projectionModelSetting = "projection_model"
+
+// ReplaceProjection swaps out the model and every point after a refit.
+func (s *SQLiteStorage) ReplaceProjection(model projection.Model, points []projection.Point) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM entry_points")
+	if err != nil {
+		return err
+	}
+
+	err = insertPoints(tx, points)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	err = setSetting(tx, projectionModelSetting, string(encoded))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddPoints places entries on the existing map.
+func (s *SQLiteStorage) AddPoints(points []projection.Point) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insertPoints(tx, points)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertPoints(db execer, points []projection.Point) error {
+	for _, point := range points {
+		_, err := db.Exec(`
+			INSERT INTO entry_points (entry_id, x, y) VALUES (?, ?, ?)
+			ON CONFLICT (entry_id) DO UPDATE SET x = excluded.x, y = excluded.y
+		`, point.EntryID, point.X, point.Y)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetProjectionModel returns the last fitted model, or an empty one if
+// there hasn't been a fit yet.
+func (s *SQLiteStorage) GetProjectionModel() (projection.Model, error) {
+	value, ok, err := s.GetSetting(projectionModelSetting)
+	if err != nil || !ok {
+		return projection.Model{}, err
+	}
+
+	var model projection.Model
+	err = json.Unmarshal([]byte(value), &model)
+
+	return model, err
+}
+
+func (s *SQLiteStorage) GetPoints() ([]projection.Point, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT entry_id, x, y FROM entry_points")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []projection.Point
+
+	for rows.Next() {
+		var point projection.Point
+		err := rows.Scan(&point.EntryID, &point.X, &point.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// GetEntriesWithoutPoint returns entries that haven't been placed on the map
+// yet.
+func (s *SQLiteStorage) GetEntriesWithoutPoint() ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id NOT IN (SELECT entry_id FROM entry_points) AND deleted_at IS NULL
+		ORDER BY time
+	`)
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"os"
+	"spire/entry"
+	"spire/projection"
+	"testing"
+	"time"
+)
+
+func TestPoints(t *testing.T) {
+	testDatabasePath := "points_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	model, err := store.GetProjectionModel()
+	if err != nil || model.Size != 0 {
+		t.Fatalf("expected no model before the first fit, got %v (%v)", model, err)
+	}
+
+	var ids []int64
+	for _, content := range []string{"first", "second"} {
+		id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: content, Embedding: generateRandomEmbeddings()})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	err = store.ReplaceProjection(
+		projection.Model{Mean: entry.Vector{0, 0}, Axes: []entry.Vector{{1, 0}, {0, 1}}, Size: 1},
+		[]projection.Point{{EntryID: ids[0], X: 1, Y: 2}},
+	)
+	if err != nil {
+		t.Fatalf("error replacing projection: %v\n", err)
+	}
+
+	model, err = store.GetProjectionModel()
+	if err != nil {
+		t.Fatalf("error getting model: %v\n", err)
+	}
+
+	if model.Size != 1 || len(model.Axes) != 2 {
+		t.Errorf("expected the saved model back, got %v", model)
+	}
+
+	pending, err := store.GetEntriesWithoutPoint()
+	if err != nil {
+		t.Fatalf("error getting entries without a point: %v\n", err)
+	}
+
+	if len(pending) != 1 || pending[0].ID != ids[1] {
+		t.Fatalf("expected only the second entry to be without a point, got %v", contents(pending))
+	}
+
+	err = store.AddPoints([]projection.Point{{EntryID: ids[1], X: -1, Y: 0.5}})
+	if err != nil {
+		t.Fatalf("error adding points: %v\n", err)
+	}
+
+	points, err := store.GetPoints()
+	if err != nil {
+		t.Fatalf("error getting points: %v\n", err)
+	}
+
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points, got %v", points)
+	}
+
+	for _, point := range points {
+		if point.EntryID == ids[1] && (point.X != -1 || point.Y != 0.5) {
+			t.Errorf("expected the added point at (-1, 0.5), got %v", point)
+		}
+	}
+}
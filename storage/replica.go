@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tursodatabase/go-libsql"
+)
+
+// ReplicaConfig points an embedded replica at the libsql server that holds
+// the primary copy of the journal, like Turso or a self-hosted sqld.
+type ReplicaConfig struct {
+	PrimaryURL string
+	AuthToken  string
+	// How often to pull changes made elsewhere. Zero only pulls on startup
+	// and after this process writes.
+	SyncInterval time.Duration
+}
+
+// replicaConnector is the part of libsql.Connector storage needs, so tests
+// can stand in for it.
+type replicaConnector interface {
+	driver.Connector
+	Sync() (libsql.Replicated, error)
+	Close() error
+}
+
+// A replica is a local copy of a remote database. Reads are served locally
+// and writes go to the primary.
+type replica struct {
+	connector replicaConnector
+	// Syncs from different connections would only repeat each other's work.
+	syncMutex sync.Mutex
+}
+
+// NewReplicaStorage opens the database at name as an embedded replica of
+// config.PrimaryURL, pulling down the primary's changes first. The replica
+// stays open until Close.
+func NewReplicaStorage(name string, config ReplicaConfig) (*SQLiteStorage, error) {
+	options := []libsql.Option{libsql.WithSyncInterval(config.SyncInterval)}
+	if config.AuthToken != "" {
+		options = append(options, libsql.WithAuthToken(config.AuthToken))
+	}
+
+	connector, err := libsql.NewEmbeddedReplicaConnector(name, config.PrimaryURL, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReplicaStorage(name, connector)
+}
+
+func newReplicaStorage(name string, connector replicaConnector) (*SQLiteStorage, error) {
+	storage := &SQLiteStorage{databaseName: name, replica: &replica{connector: connector}}
+
+	err := storage.init()
+	if err != nil {
+		connector.Close()
+		return nil, err
+	}
+
+	return storage, nil
+}
+
+// Close stops syncing and closes the replica, if there is one.
+func (s *SQLiteStorage) Close() error {
+	if s.replica == nil {
+		return nil
+	}
+
+	return s.replica.connector.Close()
+}
+
+// Sync pulls the primary's latest changes into the replica. It does nothing
+// for a plain local database.
+func (s *SQLiteStorage) Sync() error {
+	if s.replica == nil {
+		return nil
+	}
+
+	return s.replica.sync()
+}
+
+func (r *replica) sync() error {
+	r.syncMutex.Lock()
+	defer r.syncMutex.Unlock()
+
+	_, err := r.connector.Sync()
+	return err
+}
+
+// open returns a connection pool for one storage call. database/sql closes a
+// pool's connector along with the pool, which would close the replica out
+// from under every other call, so each pool gets a wrapper whose Close syncs
+// instead, if anything was written.
+func (r *replica) open() *sql.DB {
+	return sql.OpenDB(&replicaPool{replica: r})
+}
+
+type replicaPool struct {
+	replica *replica
+	wrote   atomic.Bool
+}
+
+func (p *replicaPool) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := p.replica.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replicaConn{Conn: conn, pool: p}, nil
+}
+
+func (p *replicaPool) Driver() driver.Driver {
+	return p.replica.connector.Driver()
+}
+
+func (p *replicaPool) Close() error {
+	if !p.wrote.Load() {
+		return nil
+	}
+
+	// Storage calls close their pool with a bare defer, so this is the only
+	// place a failed sync would be noticed.
+	err := p.replica.sync()
+	if err != nil {
+		log.Printf("error syncing replica: %v\n", err)
+	}
+
+	return err
+}
+
+// replicaConn notes when a statement is executed, which is how every write
+// in storage is made. It passes everything else through to libsql.
+type replicaConn struct {
+	driver.Conn
+	pool *replicaPool
+}
+
+func (c *replicaConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.pool.wrote.Store(true)
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *replicaConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *replicaConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *replicaConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+
+	"github.com/tursodatabase/go-libsql"
+)
+
+// fakeReplica is a plain local database that counts syncs, standing in for
+// an embedded replica.
+type fakeReplica struct {
+	driver.Connector
+	syncs  int
+	closed bool
+}
+
+func (f *fakeReplica) Sync() (libsql.Replicated, error) {
+	f.syncs++
+	return libsql.Replicated{}, nil
+}
+
+func (f *fakeReplica) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestReplicaSyncsAfterWrites(t *testing.T) {
+	testDatabasePath := "replica_test.db"
+
+	db, err := sql.Open("libsql", "file:"+testDatabasePath)
+	if err != nil {
+		t.Fatalf("error opening database: %v\n", err)
+	}
+	defer db.Close()
+
+	connector, err := db.Driver().(driver.DriverContext).OpenConnector("file:" + testDatabasePath)
+	if err != nil {
+		t.Fatalf("error opening connector: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	fake := &fakeReplica{Connector: connector}
+
+	store, err := newReplicaStorage(testDatabasePath, fake)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	fake.syncs = 0
+
+	_, err = store.SaveEntry(entry.Entry{Time: time.Now(), Content: "synced", Embedding: generateRandomEmbeddings()})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	if fake.syncs != 1 {
+		t.Errorf("expected a sync after saving, got %d", fake.syncs)
+	}
+
+	// Closing each call's pool must leave the replica open for the next.
+	entries, err := store.GetEntries()
+	if err != nil {
+		t.Fatalf("error getting entries: %v\n", err)
+	}
+
+	if len(entries) != 1 || fake.closed {
+		t.Fatalf("expected the entry to be read back from the open replica, got %v", contents(entries))
+	}
+
+	if fake.syncs != 1 {
+		t.Errorf("expected no sync after only reading, got %d", fake.syncs)
+	}
+
+	err = store.Close()
+	if err != nil || !fake.closed {
+		t.Errorf("expected Close to close the replica, got %v", err)
+	}
+}
+
+// TestReplicaAgainstPrimary runs against a real libsql server, such as
+// sqld started with `docker run -p 8081:8080 ghcr.io/tursodatabase/libsql-server`
+// and SPIRE_TEST_PRIMARY_URL=http://127.0.0.1:8081.
+func TestReplicaAgainstPrimary(t *testing.T) {
+	primaryURL := os.Getenv("SPIRE_TEST_PRIMARY_URL")
+	if primaryURL == "" {
+		t.Skip("SPIRE_TEST_PRIMARY_URL is not set")
+	}
+
+	config := ReplicaConfig{PrimaryURL: primaryURL, AuthToken: os.Getenv("SPIRE_TEST_AUTH_TOKEN")}
+	laptopPath := t.TempDir() + "/laptop.db"
+	serverPath := t.TempDir() + "/server.db"
+
+	laptop, err := NewReplicaStorage(laptopPath, config)
+	if err != nil {
+		t.Fatalf("error opening laptop replica: %v\n", err)
+	}
+	defer laptop.Close()
+
+	server, err := NewReplicaStorage(serverPath, config)
+	if err != nil {
+		t.Fatalf("error opening server replica: %v\n", err)
+	}
+	defer server.Close()
+
+	content := "written on the laptop at " + time.Now().Format(time.RFC3339Nano)
+
+	id, err := laptop.SaveEntry(entry.Entry{Time: time.Now(), Content: content, Embedding: generateRandomEmbeddings()})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	err = server.Sync()
+	if err != nil {
+		t.Fatalf("error syncing: %v\n", err)
+	}
+
+	e, err := server.GetEntry(id)
+	if err != nil || e.Content != content {
+		t.Fatalf("expected the server's replica to see the laptop's entry, got %v (%v)", e.Content, err)
+	}
+}
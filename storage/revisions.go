@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// A Revision is an earlier version of an entry's content.
+type Revision struct {
+	ID      int64
+	EntryID int64
+	Content string
+	// When an update replaced this version. It was written when the revision
+	// before it was replaced, or when the entry was, for the first one.
+	ReplacedAt time.Time
+}
+
+// GetRevisions returns an entry's earlier versions, oldest first.
+func (s *SQLiteStorage) GetRevisions(entryID int64) ([]Revision, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT id, entry_id, content, replaced_at
+		FROM entry_revisions
+		WHERE entry_id = ?
+		ORDER BY id
+	`, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []Revision
+
+	for rows.Next() {
+		revision, err := s.scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (s *SQLiteStorage) GetRevision(entryID int64, id int64) (Revision, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return Revision{}, err
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`
+		SELECT id, entry_id, content, replaced_at
+		FROM entry_revisions
+		WHERE entry_id = ? AND id = ?
+	`, entryID, id)
+
+	revision, err := s.scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Revision{}, ErrRevisionNotFound
+	}
+
+	return revision, err
+}
+
+func (s *SQLiteStorage) scanRevision(row scanner) (Revision, error) {
+	var revision Revision
+	var replacedAt string
+
+	err := row.Scan(&revision.ID, &revision.EntryID, &revision.Content, &replacedAt)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	revision.ReplacedAt, err = parseTimestamp(replacedAt)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	revision.Content, err = s.openContent(revisionContent, revision.ID, revision.Content)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return revision, nil
+}
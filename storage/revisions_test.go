@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestRevisions(t *testing.T) {
+	testDatabasePath := "revisions_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+
+	id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "first draft", Embedding: embedding})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	for _, content := range []string{"second draft", "second draft", "final"} {
+		err = store.UpdateEntry(entry.Entry{ID: id, Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error updating entry: %v\n", err)
+		}
+	}
+
+	revisions, err := store.GetRevisions(id)
+	if err != nil {
+		t.Fatalf("error getting revisions: %v\n", err)
+	}
+
+	// Saving the same content again isn't a new version.
+	if len(revisions) != 2 || revisions[0].Content != "first draft" || revisions[1].Content != "second draft" {
+		t.Fatalf("expected the two replaced versions, oldest first, got %v", revisions)
+	}
+
+	if revisions[0].ReplacedAt.IsZero() || revisions[1].ReplacedAt.Before(revisions[0].ReplacedAt) {
+		t.Errorf("expected replacement times in order, got %v", revisions)
+	}
+
+	revision, err := store.GetRevision(id, revisions[1].ID)
+	if err != nil || revision.Content != "second draft" {
+		t.Errorf("expected to get a revision by ID, got %v (%v)", revision, err)
+	}
+
+	_, err = store.GetRevision(id+1, revisions[1].ID)
+	if !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound for another entry's revision, got %v", err)
+	}
+
+	err = store.UpdateEntry(entry.Entry{ID: id + 100, Content: "missing", Embedding: embedding})
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	err = store.DeleteEntry(id)
+	if err == nil {
+		_, err = store.PurgeEntriesDeletedBefore(time.Now().Add(time.Hour))
+	}
+	if err != nil {
+		t.Fatalf("error purging entry: %v\n", err)
+	}
+
+	revisions, err = store.GetRevisions(id)
+	if err != nil || len(revisions) != 0 {
+		t.Errorf("expected purging to remove revisions, got %v (%v)", revisions, err)
+	}
+}
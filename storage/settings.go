@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Settings are small pieces of state that don't deserve their own table.
+
+func (s *SQLiteStorage) GetSetting(key string) (string, bool, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return "", false, err
+	}
+	defer db.Close()
+
+	var value string
+	err = db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, true, nil
+}
+
+func (s *SQLiteStorage) SetSetting(key string, value string) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return setSetting(db, key, value)
+}
+
+func setSetting(db execer, key string, value string) error {
+	_, err := db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value
+	`, key, value)
+
+	return err
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// A Share is a public, read-only link to a single entry. Like API tokens,
+// only the hash of the link's token is kept.
+type Share struct {
+	ID        int64
+	EntryID   int64
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+	// How many times the link has been opened, and when it last was.
+	Views        int
+	LastViewedAt *time.Time
+}
+
+// Active reports whether the link still shows the entry.
+func (s Share) Active(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// One opening of a share link.
+type ShareView struct {
+	ViewedAt   time.Time
+	RemoteAddr string
+	UserAgent  string
+}
+
+var ErrShareNotFound = errors.New("share link not found")
+
+const shareColumns = `
+	id, entry_id, created_at, expires_at, revoked_at,
+	(SELECT COUNT(*) FROM share_views WHERE share_id = entry_shares.id),
+	(SELECT viewed_at FROM share_views WHERE share_id = entry_shares.id ORDER BY id DESC LIMIT 1)
+`
+
+// CreateShare stores a share link for an entry. It returns ErrEntryNotFound
+// if the entry doesn't exist or is in the trash.
+func (s *SQLiteStorage) CreateShare(entryID int64, hash string, createdAt time.Time, expiresAt *time.Time) (int64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	result, err := db.Exec(`
+		INSERT INTO entry_shares (entry_id, token_hash, created_at, expires_at)
+		SELECT id, ?, ?, ? FROM entries WHERE id = ? AND deleted_at IS NULL
+	`, hash, createdAt, expiresAt, entryID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if affected == 0 {
+		return 0, ErrEntryNotFound
+	}
+
+	return result.LastInsertId()
+}
+
+// GetShares returns an entry's share links, newest first, including revoked
+// and expired ones.
+func (s *SQLiteStorage) GetShares(entryID int64) ([]Share, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT "+shareColumns+" FROM entry_shares WHERE entry_id = ? ORDER BY id DESC", entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []Share
+
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		shares = append(shares, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// GetShareByHash returns ErrShareNotFound if no share link has the given
+// hash. Expiry and revocation are left to the caller.
+func (s *SQLiteStorage) GetShareByHash(hash string) (Share, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return Share{}, err
+	}
+	defer db.Close()
+
+	row := db.QueryRow("SELECT "+shareColumns+" FROM entry_shares WHERE token_hash = ?", hash)
+
+	share, err := scanShare(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Share{}, ErrShareNotFound
+	}
+
+	return share, err
+}
+
+// RevokeShare stops a share link from working. The link is kept, rather than
+// deleted like a revoked API token, so its views stay on record.
+func (s *SQLiteStorage) RevokeShare(entryID int64, id int64, revokedAt time.Time) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Exec(
+		"UPDATE entry_shares SET revoked_at = ? WHERE id = ? AND entry_id = ? AND revoked_at IS NULL",
+		revokedAt, id, entryID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) LogShareView(shareID int64, view ShareView) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(
+		"INSERT INTO share_views (share_id, viewed_at, remote_addr, user_agent) VALUES (?, ?, ?, ?)",
+		shareID, view.ViewedAt, view.RemoteAddr, view.UserAgent,
+	)
+	return err
+}
+
+// GetShareViews returns the most recent views of a share link, newest first.
+func (s *SQLiteStorage) GetShareViews(shareID int64, limit int) ([]ShareView, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT viewed_at, remote_addr, user_agent
+		FROM share_views
+		WHERE share_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, shareID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []ShareView
+
+	for rows.Next() {
+		var view ShareView
+		var viewedAt string
+
+		err := rows.Scan(&viewedAt, &view.RemoteAddr, &view.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+
+		view.ViewedAt, err = parseTimestamp(viewedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func scanShare(row scanner) (Share, error) {
+	var share Share
+	var createdAt string
+	var expiresAt, revokedAt, lastViewedAt sql.NullString
+
+	err := row.Scan(&share.ID, &share.EntryID, &createdAt, &expiresAt, &revokedAt, &share.Views, &lastViewedAt)
+	if err != nil {
+		return Share{}, err
+	}
+
+	share.CreatedAt, err = parseTimestamp(createdAt)
+	if err != nil {
+		return Share{}, err
+	}
+
+	share.ExpiresAt, err = parseNullTimestamp(expiresAt)
+	if err != nil {
+		return Share{}, err
+	}
+
+	share.RevokedAt, err = parseNullTimestamp(revokedAt)
+	if err != nil {
+		return Share{}, err
+	}
+
+	share.LastViewedAt, err = parseNullTimestamp(lastViewedAt)
+	if err != nil {
+		return Share{}, err
+	}
+
+	return share, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"spire/token"
+	"testing"
+	"time"
+)
+
+func TestShares(t *testing.T) {
+	testDatabasePath := "shares_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "hello", Embedding: greetingEmbeddings})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	_, hash, err := token.GenerateWithPrefix(token.SharePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	shareID, err := store.CreateShare(id, hash, now, &expiresAt)
+	if err != nil {
+		t.Fatalf("error creating share: %v\n", err)
+	}
+
+	share, err := store.GetShareByHash(hash)
+	if err != nil {
+		t.Fatalf("error getting share by hash: %v\n", err)
+	}
+
+	if share.ID != shareID || share.EntryID != id || share.Views != 0 || share.LastViewedAt != nil {
+		t.Errorf("unexpected share %+v", share)
+	}
+
+	if !share.Active(now) || share.Active(expiresAt) {
+		t.Errorf("expected the share to be active until it expires")
+	}
+
+	for _, address := range []string{"192.0.2.1:1234", "192.0.2.2:5678"} {
+		err = store.LogShareView(shareID, ShareView{ViewedAt: time.Now(), RemoteAddr: address, UserAgent: "curl"})
+		if err != nil {
+			t.Fatalf("error logging share view: %v\n", err)
+		}
+	}
+
+	views, err := store.GetShareViews(shareID, 1)
+	if err != nil || len(views) != 1 || views[0].RemoteAddr != "192.0.2.2:5678" {
+		t.Errorf("expected only the latest view, got %v (%v)", views, err)
+	}
+
+	err = store.RevokeShare(id, shareID, now)
+	if err != nil {
+		t.Fatalf("error revoking share: %v\n", err)
+	}
+
+	err = store.RevokeShare(id, shareID, now)
+	if !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("expected ErrShareNotFound revoking twice, got %v", err)
+	}
+
+	shares, err := store.GetShares(id)
+	if err != nil || len(shares) != 1 {
+		t.Fatalf("expected the revoked share to be kept, got %v (%v)", shares, err)
+	}
+
+	if shares[0].Active(now) || shares[0].Views != 2 || shares[0].LastViewedAt == nil {
+		t.Errorf("expected a revoked share with two views, got %+v", shares[0])
+	}
+
+	_, err = store.GetShareByHash(token.Hash(token.SharePrefix + "missing"))
+	if !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("expected ErrShareNotFound, got %v", err)
+	}
+
+	_, err = store.CreateShare(id+1, hash+"x", now, nil)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound sharing a missing entry, got %v", err)
+	}
+
+	err = store.DeleteEntry(id)
+	if err != nil {
+		t.Fatalf("error deleting entry: %v\n", err)
+	}
+
+	err = store.PurgeEntry(id)
+	if err != nil {
+		t.Fatalf("error purging entry: %v\n", err)
+	}
+
+	_, err = store.GetShareByHash(hash)
+	if !errors.Is(err, ErrShareNotFound) {
+		t.Errorf("expected purging the entry to delete its shares, got %v", err)
+	}
+}
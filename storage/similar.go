@@ -0,0 +1,42 @@
+package storage
+
+import "spire/entry"
+
+// GetSimilarEntries returns the entries closest to the given one, using the
+// embedding already stored for it. The entry itself is left out.
+func (s *SQLiteStorage) GetSimilarEntries(id int64, limit int) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var exists bool
+	err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM entries WHERE id = ? AND deleted_at IS NULL)", id).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrEntryNotFound
+	}
+
+	// Ask the index for one extra result, since the entry is its own nearest
+	// neighbor.
+	candidates, err := vectorCandidates(db, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id IN (
+			SELECT id FROM vector_top_k('entries_idx', (SELECT embedding FROM entries WHERE id = ?), ?)
+		)
+			AND id != ?
+			AND deleted_at IS NULL
+		ORDER BY vector_distance_cos(embedding, (SELECT embedding FROM entries WHERE id = ?))
+		LIMIT ?
+	`, id, candidates, id, id, limit)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestSimilarEntries(t *testing.T) {
+	testDatabasePath := "similar_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	base := generateRandomEmbeddings()
+
+	// A slightly nudged copy of base is closer to it than an unrelated vector.
+	nudged := make(entry.Vector, len(base))
+	copy(nudged, base)
+	nudged[0] += 0.1
+
+	ids := map[string]int64{}
+	for name, embedding := range map[string]entry.Vector{
+		"base":      base,
+		"nudged":    nudged,
+		"greeting":  greetingEmbeddings,
+		"unrelated": invert(base),
+	} {
+		ids[name], err = store.SaveEntry(entry.Entry{Time: time.Now(), Content: name, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+	}
+
+	similar, err := store.GetSimilarEntries(ids["base"], 2)
+	if err != nil {
+		t.Fatalf("error getting similar entries: %v\n", err)
+	}
+
+	if len(similar) != 2 {
+		t.Fatalf("expected 2 similar entries, got %d", len(similar))
+	}
+
+	if similar[0].ID != ids["nudged"] {
+		t.Errorf("expected the nudged entry first, got %q", similar[0].Content)
+	}
+
+	for _, e := range similar {
+		if e.ID == ids["base"] {
+			t.Errorf("entry should not be similar to itself")
+		}
+	}
+
+	_, err = store.GetSimilarEntries(-1, 2)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func invert(v entry.Vector) entry.Vector {
+	result := make(entry.Vector, len(v))
+	for i := range v {
+		result[i] = -v[i]
+	}
+
+	return result
+}
@@ -2,9 +2,12 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"spire/entry"
+	"spire/seal"
+	"strings"
 	"time"
 
 	_ "github.com/tursodatabase/go-libsql"
@@ -12,6 +15,10 @@ import (
 
 type SQLiteStorage struct {
 	databaseName string
+	// Set when the database is an embedded replica of a remote one.
+	replica *replica
+	// Set once an encrypted journal has been unlocked.
+	key *seal.Key
 }
 
 func NewSQLiteStorage(name string) (*SQLiteStorage, error) {
@@ -25,13 +32,31 @@ func NewSQLiteStorage(name string) (*SQLiteStorage, error) {
 	return storage, nil
 }
 
+// How long a connection waits for another one's write to finish before
+// giving up with "database is locked". Background jobs write while requests
+// are being served, so some waiting is expected.
+const busyTimeout = 5000 * time.Millisecond
+
 func (s *SQLiteStorage) getDatabaseConnection() (*sql.DB, error) {
+	if s.replica != nil {
+		// Writes go to the primary, so there's no local lock to wait for.
+		return s.replica.open(), nil
+	}
+
 	db, err := sql.Open("libsql", "file:"+s.databaseName)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// The pragma returns the new value, so it has to be read as a query.
+	var timeout int64
+	err = db.QueryRow(fmt.Sprintf("PRAGMA busy_timeout = %d", busyTimeout.Milliseconds())).Scan(&timeout)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return db, nil
 }
 
@@ -59,22 +84,257 @@ func (s *SQLiteStorage) init() error {
 		return err
 	}
 
-	return nil
-}
+	// Set when an entry is moved to the trash. Entries in the trash are left
+	// out of everything but the trash itself.
+	err = addColumn(db, "entries", "deleted_at", "TIMESTAMP")
+	if err != nil {
+		return err
+	}
 
-func (s *SQLiteStorage) SaveEntry(e entry.Entry) error {
-	db, err := s.getDatabaseConnection()
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entries_deleted_at_idx ON entries (deleted_at)")
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
-	queryTemplate := fmt.Sprintf(
-		"INSERT INTO entries (time, content, embedding) VALUES (?, ?, %s);",
-		entry.SerializeEmbeddingsWithVectorPrefix(e.Embedding),
+	// Private entries are hidden until the password is entered again.
+	err = addColumn(db, "entries", "private", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS notebooks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Every journal has at least the notebook its entries start in.
+	_, err = db.Exec(
+		"INSERT INTO notebooks (id, name) SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM notebooks)",
+		DefaultNotebookID, defaultNotebookName,
 	)
+	if err != nil {
+		return err
+	}
+
+	// SQLite won't add a column that references another table with a
+	// default other than NULL, so this one doesn't say what it points at.
+	err = addColumn(db, "entries", "notebook_id", fmt.Sprintf("INTEGER NOT NULL DEFAULT %d", DefaultNotebookID))
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entries_notebook_idx ON entries (notebook_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_tags (
+			entry_id INTEGER NOT NULL REFERENCES entries (id),
+			tag TEXT NOT NULL,
+			PRIMARY KEY (entry_id, tag)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entry_tags_tag_idx ON entry_tags (tag)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_links (
+			source_id INTEGER NOT NULL REFERENCES entries (id),
+			label TEXT NOT NULL,
+			target_id INTEGER REFERENCES entries (id),
+			PRIMARY KEY (source_id, label)
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entry_links_target_idx ON entry_links (target_id)")
+	if err != nil {
+		return err
+	}
+
+	// Earlier versions of an entry's content, each kept when an update
+	// replaced it.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries (id),
+			content TEXT NOT NULL,
+			replaced_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entry_revisions_entry_idx ON entry_revisions (entry_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries (id),
+			name TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			hash TEXT NOT NULL,
+			thumbnail_hash TEXT
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS attachments_entry_idx ON attachments (entry_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS pending_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time TIMESTAMP NOT NULL,
+			content TEXT NOT NULL,
+			tags TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
 
-	_, err = db.Exec(queryTemplate, e.Time, e.Content)
+	err = addColumn(db, "pending_entries", "private", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS topics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			keywords TEXT NOT NULL,
+			centroid TEXT NOT NULL,
+			size INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_topics (
+			entry_id INTEGER PRIMARY KEY REFERENCES entries (id),
+			topic_id INTEGER NOT NULL REFERENCES topics (id),
+			similarity REAL NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entry_topics_topic_idx ON entry_topics (topic_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS themes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			vector TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_points (
+			entry_id INTEGER PRIMARY KEY REFERENCES entries (id),
+			x REAL NOT NULL,
+			y REAL NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS api_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			scopes TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			last_used_at TIMESTAMP,
+			expires_at TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_shares (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL REFERENCES entries (id),
+			token_hash TEXT NOT NULL UNIQUE,
+			created_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS entry_shares_entry_idx ON entry_shares (entry_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS share_views (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			share_id INTEGER NOT NULL REFERENCES entry_shares (id),
+			viewed_at TIMESTAMP NOT NULL,
+			remote_addr TEXT NOT NULL,
+			user_agent TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS share_views_share_idx ON share_views (share_id)")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion))
 	if err != nil {
 		return err
 	}
@@ -82,108 +342,378 @@ func (s *SQLiteStorage) SaveEntry(e entry.Entry) error {
 	return nil
 }
 
-func (s *SQLiteStorage) GetEntries() ([]entry.Entry, error) {
+// addColumn adds a column to a table created before the column existed. It
+// does nothing if the column is already there.
+func addColumn(db *sql.DB, table string, column string, definition string) error {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pragma_table_info(?) WHERE name = ?)", table, column).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// The driver writes timestamps in RFC 3339 format, using "Z" for UTC and a
+// numeric offset otherwise.
+func parseTimestamp(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+var ErrEntryNotFound = errors.New("entry not found")
+
+// The columns scanEntries expects, in order.
+const entryColumns = "id, time, content, vector_extract(embedding), private, notebook_id"
+
+// SaveEntry stores the entry along with its tags and links, and returns its
+// new ID. Hashtags in the content are added to the entry's tags
+// automatically.
+func (s *SQLiteStorage) SaveEntry(e entry.Entry) (int64, error) {
+	ids, err := s.SaveEntries([]entry.Entry{e})
+	if err != nil {
+		return 0, err
+	}
+
+	return ids[0], nil
+}
+
+// SaveEntries stores several entries in one transaction, as for an import,
+// and returns their new IDs in order. Either all of them are saved or none
+// are.
+func (s *SQLiteStorage) SaveEntries(entries []entry.Entry) ([]int64, error) {
 	db, err := s.getDatabaseConnection()
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	rows, err := db.Query("SELECT time, content, vector_extract(embedding) FROM entries ORDER BY time DESC")
+	tx, err := db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var entries []entry.Entry
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i], err = s.insertEntry(tx, e)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	for rows.Next() {
-		var currentEntry entry.Entry
+	return ids, tx.Commit()
+}
 
-		var timeString string
-		var embeddingString string
-		err := rows.Scan(&timeString, &currentEntry.Content, &embeddingString)
+func (s *SQLiteStorage) insertEntry(tx *sql.Tx, e entry.Entry) (int64, error) {
+	queryTemplate := fmt.Sprintf(
+		"INSERT INTO entries (time, content, private, notebook_id, embedding) VALUES (?, ?, ?, ?, %s);",
+		entry.SerializeEmbeddingsWithVectorPrefix(e.Embedding),
+	)
+
+	notebookID := e.NotebookID
+	if notebookID == 0 {
+		notebookID = DefaultNotebookID
+	}
+
+	result, err := tx.Exec(queryTemplate, e.Time, "", e.Private, notebookID)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.writeContent(tx, entryContent, id, e.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	err = replaceTags(tx, id, entry.MergeTags(e.Tags, entry.ExtractTags(e.Content)))
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.replaceLinks(tx, id, e.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	err = resolveDanglingLinks(tx, id, e.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	err = insertAttachments(tx, id, e.Attachments)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateEntry replaces an entry's content, embedding and tags, keeping its
+// ID and time. If the content changed, the old content is kept as a revision.
+// Its topic and place on the map are cleared so the background jobs place it
+// again.
+func (s *SQLiteStorage) UpdateEntry(e entry.Entry) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Sealed content is different every time, so the old content has to be
+	// opened to tell whether it changed.
+	var oldContent string
+	err = tx.QueryRow("SELECT content FROM entries WHERE id = ? AND deleted_at IS NULL", e.ID).Scan(&oldContent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEntryNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.openContent(entryContent, e.ID, oldContent)
+	if err != nil {
+		return err
+	}
+
+	if previous != e.Content {
+		result, err := tx.Exec(
+			"INSERT INTO entry_revisions (entry_id, content, replaced_at) VALUES (?, ?, ?)",
+			e.ID, "", time.Now(),
+		)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		currentEntry.Time, err = time.Parse("2006-01-02T15:04:05.999999999-07:00", timeString)
+		revisionID, err := result.LastInsertId()
 		if err != nil {
-			log.Printf("Error parsing timestamp: %v\n", err)
-			return nil, err
+			return err
 		}
 
-		currentEntry.Embedding, err = entry.DeserializeEmbeddings(embeddingString)
+		err = s.writeContent(tx, revisionContent, revisionID, previous)
 		if err != nil {
-			log.Printf("Error parsing embeddings: %v\n", err)
-			return nil, err
+			return err
 		}
+	}
 
-		entries = append(entries, currentEntry)
+	query := fmt.Sprintf(
+		"UPDATE entries SET embedding = %s WHERE id = ?",
+		entry.SerializeEmbeddingsWithVectorPrefix(e.Embedding),
+	)
+
+	_, err = tx.Exec(query, e.ID)
+	if err != nil {
+		return err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	err = s.writeContent(tx, entryContent, e.ID, e.Content)
+	if err != nil {
+		return err
 	}
 
-	return entries, nil
+	err = replaceTags(tx, e.ID, entry.MergeTags(e.Tags, entry.ExtractTags(e.Content)))
+	if err != nil {
+		return err
+	}
+
+	err = s.replaceLinks(tx, e.ID, e.Content)
+	if err != nil {
+		return err
+	}
+
+	err = resolveDanglingLinks(tx, e.ID, e.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM entry_topics WHERE entry_id = ?", e.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM entry_points WHERE entry_id = ?", e.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (s *SQLiteStorage) SearchEntries(query string) ([]entry.Entry, error) {
-	// TODO: This is repeated quite a bit. Is there a better way, maybe
-	// something similar to Python's ContextManager?
+// SetEntryPrivate hides an entry behind the password, or stops hiding it.
+func (s *SQLiteStorage) SetEntryPrivate(id int64, private bool) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Exec("UPDATE entries SET private = ? WHERE id = ? AND deleted_at IS NULL", private, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+
+	return nil
+}
+
+// GetPrivateEntryIDs returns the IDs of every private entry, so links to
+// them can be told apart without loading them.
+func (s *SQLiteStorage) GetPrivateEntryIDs() (map[int64]bool, error) {
 	db, err := s.getDatabaseConnection()
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	// TODO: I don't really need the embedding here, but I'm getting it because
-	// my test uses it, and it doesn't feel right to leave the struct field
-	// empty.
-	rows, err := db.Query(`
-		SELECT time, content, vector_extract(embedding)
-		FROM entries
-		WHERE content LIKE ?
-		ORDER BY time DESC
-	`, "%"+query+"%")
+	rows, err := db.Query("SELECT id FROM entries WHERE private = 1 AND deleted_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var entries []entry.Entry
-
+	ids := map[int64]bool{}
 	for rows.Next() {
-		var currentEntry entry.Entry
-
-		var timeString string
-		var embeddingString string
-		err := rows.Scan(&timeString, &currentEntry.Content, &embeddingString)
+		var id int64
+		err := rows.Scan(&id)
 		if err != nil {
 			return nil, err
 		}
 
-		currentEntry.Time, err = time.Parse("2006-01-02T15:04:05.999999999-07:00", timeString)
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStorage) GetEntry(id int64) (entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return entry.Entry{}, err
+	}
+	defer db.Close()
+
+	entries, err := s.queryEntries(db, "SELECT "+entryColumns+" FROM entries WHERE id = ? AND deleted_at IS NULL", id)
+	if err != nil {
+		return entry.Entry{}, err
+	}
+
+	if len(entries) == 0 {
+		return entry.Entry{}, ErrEntryNotFound
+	}
+
+	return entries[0], nil
+}
+
+func (s *SQLiteStorage) GetEntries() ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, "SELECT "+entryColumns+" FROM entries WHERE deleted_at IS NULL ORDER BY time DESC")
+}
+
+// ForEachEntry calls fn with every entry, oldest first, loading them a batch
+// at a time so even a large journal never has to fit in memory. It stops at
+// the first error fn returns.
+func (s *SQLiteStorage) ForEachEntry(fn func(entry.Entry) error) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var lastID int64
+	for {
+		entries, err := s.queryEntries(db, `
+			SELECT `+entryColumns+`
+			FROM entries
+			WHERE id > ? AND deleted_at IS NULL
+			ORDER BY id
+			LIMIT ?
+		`, lastID, entryBatchSize)
 		if err != nil {
-			log.Printf("Error parsing timestamp: %v\n", err)
-			return nil, err
+			return err
 		}
 
-		currentEntry.Embedding, err = entry.DeserializeEmbeddings(embeddingString)
-		if err != nil {
-			log.Printf("Error parsing embeddings: %v\n", err)
-			return nil, err
+		for _, e := range entries {
+			err = fn(e)
+			if err != nil {
+				return err
+			}
 		}
 
-		entries = append(entries, currentEntry)
+		if len(entries) < entryBatchSize {
+			return nil
+		}
+
+		lastID = entries[len(entries)-1].ID
 	}
+}
 
-	if err := rows.Err(); err != nil {
+func (s *SQLiteStorage) SearchEntries(query string) ([]entry.Entry, error) {
+	// TODO: This is repeated quite a bit. Is there a better way, maybe
+	// something similar to Python's ContextManager?
+	db, err := s.getDatabaseConnection()
+	if err != nil {
 		return nil, err
 	}
+	defer db.Close()
 
-	return entries, nil
+	if s.key != nil {
+		return s.searchSealedEntries(db, query)
+	}
+
+	// TODO: I don't really need the embedding here, but I'm getting it because
+	// my test uses it, and it doesn't feel right to leave the struct field
+	// empty.
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE content LIKE ? AND deleted_at IS NULL
+		ORDER BY time DESC
+	`, "%"+query+"%")
+}
+
+// searchSealedEntries does what LIKE would, for content the database can't
+// read: it opens every entry and keeps those containing the query, ignoring
+// case.
+func (s *SQLiteStorage) searchSealedEntries(db *sql.DB, query string) ([]entry.Entry, error) {
+	entries, err := s.queryEntries(db, "SELECT "+entryColumns+" FROM entries WHERE deleted_at IS NULL ORDER BY time DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+
+	var matches []entry.Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Content), query) {
+			matches = append(matches, e)
+		}
+	}
+
+	return matches, nil
 }
 
 func (s *SQLiteStorage) SearchEntriesEmbedding(embedding entry.Vector) ([]entry.Entry, error) {
@@ -194,12 +724,19 @@ func (s *SQLiteStorage) SearchEntriesEmbedding(embedding entry.Vector) ([]entry.
 	defer db.Close()
 
 	query := fmt.Sprintf(`
-		SELECT time, content, vector_extract(embedding)
+		SELECT `+entryColumns+`
 		FROM entries
+		WHERE deleted_at IS NULL
 		ORDER BY vector_distance_cos(embedding, vector(%s))
 	`, entry.SerializeEmbeddings(embedding))
 
-	rows, err := db.Query(query)
+	return s.queryEntries(db, query)
+}
+
+// queryEntries runs a query selecting entryColumns and fills in each entry's
+// tags and links.
+func (s *SQLiteStorage) queryEntries(db *sql.DB, query string, args ...any) ([]entry.Entry, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -208,27 +745,53 @@ func (s *SQLiteStorage) SearchEntriesEmbedding(embedding entry.Vector) ([]entry.
 	var entries []entry.Entry
 
 	for rows.Next() {
-		var entry entry.Entry
+		var currentEntry entry.Entry
 
 		var timeString string
-		var embeddingStr string
-		err := rows.Scan(&timeString, &entry.Content, &embeddingStr)
+		var embeddingString string
+		err := rows.Scan(&currentEntry.ID, &timeString, &currentEntry.Content, &embeddingString, &currentEntry.Private, &currentEntry.NotebookID)
 		if err != nil {
 			return nil, err
 		}
 
-		entry.Time, err = time.Parse("2006-01-02T15:04:05.999999999-07:00", timeString)
+		currentEntry.Time, err = parseTimestamp(timeString)
 		if err != nil {
 			log.Printf("Error parsing timestamp: %v\n", err)
 			return nil, err
 		}
 
-		entries = append(entries, entry)
+		currentEntry.Content, err = s.openContent(entryContent, currentEntry.ID, currentEntry.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		currentEntry.Embedding, err = entry.DeserializeEmbeddings(embeddingString)
+		if err != nil {
+			log.Printf("Error parsing embeddings: %v\n", err)
+			return nil, err
+		}
+
+		entries = append(entries, currentEntry)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	err = loadTags(db, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadLinks(db, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadAttachments(db, entries)
+	if err != nil {
+		return nil, err
+	}
+
 	return entries, nil
 }
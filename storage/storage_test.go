@@ -1,8 +1,12 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"spire/entry"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,7 +40,7 @@ func TestStorage(t *testing.T) {
 	}
 
 	for _, e := range originalEntries {
-		err = store.SaveEntry(e)
+		_, err = store.SaveEntry(e)
 		if err != nil {
 			t.Errorf("error saving entry: %v\n", err)
 			t.FailNow()
@@ -80,6 +84,185 @@ func TestStorage(t *testing.T) {
 	}
 }
 
+func TestSaveEntries(t *testing.T) {
+	testDatabasePath := "save_entries_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	ids, err := store.SaveEntries([]entry.Entry{
+		{Time: time.Now(), Content: "# Lisbon\ntrams", Embedding: generateRandomEmbeddings()},
+		{Time: time.Now(), Content: "back from [[Lisbon]]", Embedding: greetingEmbeddings, Tags: []string{"travel"}},
+	})
+	if err != nil {
+		t.Fatalf("error saving entries: %v\n", err)
+	}
+
+	if len(ids) != 2 || ids[1] <= ids[0] {
+		t.Fatalf("expected two increasing IDs, got %v", ids)
+	}
+
+	second, err := store.GetEntry(ids[1])
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	if len(second.Links) != 1 || second.Links[0].TargetID != ids[0] {
+		t.Errorf("expected the link to resolve to the first entry, got %v", second.Links)
+	}
+
+	if strings.Join(second.Tags, ",") != "travel" {
+		t.Errorf("expected the given tags, got %v", second.Tags)
+	}
+}
+
+func TestForEachEntry(t *testing.T) {
+	testDatabasePath := "for_each_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	// More than one batch, to cover picking up where the last one ended.
+	entries := make([]entry.Entry, entryBatchSize+3)
+	for i := range entries {
+		entries[i] = entry.Entry{Time: time.Now(), Content: strconv.Itoa(i), Embedding: greetingEmbeddings}
+	}
+
+	_, err = store.SaveEntries(entries)
+	if err != nil {
+		t.Fatalf("error saving entries: %v\n", err)
+	}
+
+	seen := 0
+	err = store.ForEachEntry(func(e entry.Entry) error {
+		if e.Content != strconv.Itoa(seen) {
+			return fmt.Errorf("expected entry %d, got %q", seen, e.Content)
+		}
+
+		seen++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking entries: %v\n", err)
+	}
+
+	if seen != len(entries) {
+		t.Errorf("expected %d entries, saw %d", len(entries), seen)
+	}
+}
+
+func TestUpdateEntry(t *testing.T) {
+	testDatabasePath := "update_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	id, err := store.SaveEntry(entry.Entry{
+		Time:      time.Now(),
+		Content:   "walked the dog #outside",
+		Embedding: generateRandomEmbeddings(),
+	})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	err = store.UpdateEntry(entry.Entry{
+		ID:        id,
+		Content:   "walked the dog #outside\n\nthen coffee #morning",
+		Embedding: greetingEmbeddings,
+		Tags:      []string{"outside", "pets"},
+	})
+	if err != nil {
+		t.Fatalf("error updating entry: %v\n", err)
+	}
+
+	updated, err := store.GetEntry(id)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	if !strings.HasSuffix(updated.Content, "then coffee #morning") {
+		t.Errorf("expected the new content, got %q", updated.Content)
+	}
+
+	if strings.Join(updated.Tags, ",") != "morning,outside,pets" {
+		t.Errorf("expected the given and extracted tags, got %v", updated.Tags)
+	}
+
+	if updated.Embedding.CosineSimilarity(greetingEmbeddings) < 0.999 {
+		t.Errorf("expected the new embedding to be stored")
+	}
+
+	err = store.UpdateEntry(entry.Entry{ID: id + 100, Content: "missing", Embedding: greetingEmbeddings})
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound for a missing entry, got %v", err)
+	}
+}
+
+func TestSetEntryPrivate(t *testing.T) {
+	testDatabasePath := "private_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "a secret", Embedding: generateRandomEmbeddings(), Private: true})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	saved, err := store.GetEntry(id)
+	if err != nil || !saved.Private {
+		t.Fatalf("expected a private entry, got %v (%v)", saved, err)
+	}
+
+	// Editing an entry doesn't change whether it's private.
+	err = store.UpdateEntry(entry.Entry{ID: id, Content: "a bigger secret", Embedding: saved.Embedding})
+	if err != nil {
+		t.Fatalf("error updating entry: %v\n", err)
+	}
+
+	updated, err := store.GetEntry(id)
+	if err != nil || !updated.Private {
+		t.Errorf("expected the entry to stay private, got %v (%v)", updated, err)
+	}
+
+	private, err := store.GetPrivateEntryIDs()
+	if err != nil || len(private) != 1 || !private[id] {
+		t.Errorf("expected only the entry's ID to be private, got %v (%v)", private, err)
+	}
+
+	err = store.SetEntryPrivate(id, false)
+	if err != nil {
+		t.Fatalf("error making entry public: %v\n", err)
+	}
+
+	entries, err := store.GetEntries()
+	if err != nil || len(entries) != 1 || entries[0].Private || entries[0].Content != "a bigger secret" {
+		t.Errorf("expected the entry to be public, got %v (%v)", entries, err)
+	}
+
+	err = store.SetEntryPrivate(id+1, true)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound for a missing entry, got %v", err)
+	}
+}
+
 func generateRandomEmbeddings() entry.Vector {
 	rand.Seed(42)
 
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"spire/entry"
+)
+
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func replaceTags(db execer, entryID int64, tags []string) error {
+	_, err := db.Exec("DELETE FROM entry_tags WHERE entry_id = ?", entryID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err = db.Exec("INSERT INTO entry_tags (entry_id, tag) VALUES (?, ?)", entryID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadTags(db *sql.DB, entries []entry.Entry) error {
+	return queryByEntryIDs(db, entries,
+		"SELECT entry_id, tag FROM entry_tags WHERE entry_id IN (%s) ORDER BY tag",
+		func(rows *sql.Rows, byID map[int64]*entry.Entry) error {
+			var entryID int64
+			var tag string
+			err := rows.Scan(&entryID, &tag)
+			if err != nil {
+				return err
+			}
+
+			e := byID[entryID]
+			e.Tags = append(e.Tags, tag)
+			return nil
+		},
+	)
+}
+
+// SetEntryTags replaces an entry's tags. Hashtags in the entry's content are
+// always kept, since removing them here would only undo the next edit.
+func (s *SQLiteStorage) SetEntryTags(id int64, tags []string) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var content string
+	err = db.QueryRow("SELECT content FROM entries WHERE id = ? AND deleted_at IS NULL", id).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEntryNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	content, err = s.openContent(entryContent, id, content)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = replaceTags(tx, id, entry.MergeTags(tags, entry.ExtractTags(content)))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStorage) GetEntriesByTag(tag string) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id IN (SELECT entry_id FROM entry_tags WHERE tag = ?) AND deleted_at IS NULL
+		ORDER BY time DESC
+	`, entry.NormalizeTag(tag))
+}
+
+// GetTagCounts returns every tag with the number of entries using it, most
+// used first. Private entries' tags are only counted if includePrivate is set.
+func (s *SQLiteStorage) GetTagCounts(includePrivate bool) ([]TagCount, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT tag, COUNT(*) AS count
+		FROM entry_tags
+		WHERE entry_id IN (SELECT id FROM entries WHERE deleted_at IS NULL AND (? OR private = 0))
+		GROUP BY tag
+		ORDER BY count DESC, tag
+	`, includePrivate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+
+	for rows.Next() {
+		var count TagCount
+		err := rows.Scan(&count.Tag, &count.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
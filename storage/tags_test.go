@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	testDatabasePath := "tags_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+
+	firstID, err := store.SaveEntry(entry.Entry{
+		Time:      time.Now(),
+		Content:   "shipped the #release today #work",
+		Embedding: embedding,
+		Tags:      []string{"Milestone"},
+	})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	_, err = store.SaveEntry(entry.Entry{
+		Time:      time.Now(),
+		Content:   "long day at #work",
+		Embedding: embedding,
+	})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	first, err := store.GetEntry(firstID)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	expected := []string{"milestone", "release", "work"}
+	if !slices.Equal(expected, first.Tags) {
+		t.Errorf("expected tags %v, got %v", expected, first.Tags)
+	}
+
+	_, err = store.SaveEntry(entry.Entry{
+		Time:      time.Now(),
+		Content:   "interview elsewhere #work #secret",
+		Embedding: embedding,
+		Private:   true,
+	})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	counts, err := store.GetTagCounts(false)
+	if err != nil {
+		t.Fatalf("error getting tag counts: %v\n", err)
+	}
+
+	if len(counts) != 3 || counts[0] != (TagCount{Tag: "work", Count: 2}) {
+		t.Errorf("expected work to be the most used of 3 public tags, got %v", counts)
+	}
+
+	counts, err = store.GetTagCounts(true)
+	if err != nil {
+		t.Fatalf("error getting tag counts: %v\n", err)
+	}
+
+	if len(counts) != 4 || counts[0] != (TagCount{Tag: "work", Count: 3}) {
+		t.Errorf("expected the private entry's tags to be counted too, got %v", counts)
+	}
+
+	tagged, err := store.GetEntriesByTag("#Work")
+	if err != nil {
+		t.Fatalf("error getting entries by tag: %v\n", err)
+	}
+
+	if len(tagged) != 3 {
+		t.Errorf("expected 3 entries tagged work, got %d", len(tagged))
+	}
+
+	// Explicit tags are replaced, but hashtags from the content stay.
+	err = store.SetEntryTags(firstID, []string{"launch"})
+	if err != nil {
+		t.Fatalf("error setting tags: %v\n", err)
+	}
+
+	first, err = store.GetEntry(firstID)
+	if err != nil {
+		t.Fatalf("error getting entry: %v\n", err)
+	}
+
+	expected = []string{"launch", "release", "work"}
+	if !slices.Equal(expected, first.Tags) {
+		t.Errorf("expected tags %v, got %v", expected, first.Tags)
+	}
+
+	err = store.SetEntryTags(-1, []string{"nothing"})
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"spire/entry"
+	"spire/trends"
+)
+
+var ErrThemeNotFound = errors.New("theme not found")
+
+// SaveTheme stores a theme the user wants to follow and returns its ID.
+func (s *SQLiteStorage) SaveTheme(name string, vector entry.Vector) (int64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	encoded, err := json.Marshal(vector)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec("INSERT INTO themes (name, vector) VALUES (?, ?)", name, string(encoded))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetThemes returns the user's themes in the order they were added.
+func (s *SQLiteStorage) GetThemes() ([]trends.Theme, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name, vector FROM themes ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var themes []trends.Theme
+
+	for rows.Next() {
+		theme := trends.Theme{Custom: true}
+		var vector string
+		err := rows.Scan(&theme.ID, &theme.Name, &vector)
+		if err != nil {
+			return nil, err
+		}
+
+		theme.Vector, err = entry.DeserializeEmbeddings(vector)
+		if err != nil {
+			return nil, err
+		}
+
+		themes = append(themes, theme)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return themes, nil
+}
+
+func (s *SQLiteStorage) DeleteTheme(id int64) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM themes WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrThemeNotFound
+	}
+
+	return nil
+}
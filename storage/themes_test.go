@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/entry"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	testDatabasePath := "themes_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	stress, err := store.SaveTheme("work stress", entry.Vector{1, 0})
+	if err != nil {
+		t.Fatalf("error saving theme: %v\n", err)
+	}
+
+	_, err = store.SaveTheme("gardening", entry.Vector{0, 1})
+	if err != nil {
+		t.Fatalf("error saving theme: %v\n", err)
+	}
+
+	themes, err := store.GetThemes()
+	if err != nil {
+		t.Fatalf("error getting themes: %v\n", err)
+	}
+
+	if len(themes) != 2 || themes[0].Name != "work stress" || !themes[0].Custom || themes[1].Vector[1] != 1 {
+		t.Fatalf("expected both themes back in order, got %v", themes)
+	}
+
+	err = store.DeleteTheme(stress)
+	if err != nil {
+		t.Fatalf("error deleting theme: %v\n", err)
+	}
+
+	err = store.DeleteTheme(stress)
+	if !errors.Is(err, ErrThemeNotFound) {
+		t.Errorf("expected ErrThemeNotFound deleting twice, got %v", err)
+	}
+
+	themes, err = store.GetThemes()
+	if err != nil || len(themes) != 1 {
+		t.Errorf("expected one theme left, got %v (%v)", themes, err)
+	}
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"spire/token"
+	"time"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+func (s *SQLiteStorage) SaveToken(t token.Token, hash string) (int64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	result, err := db.Exec(
+		"INSERT INTO api_tokens (name, token_hash, scopes, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
+		t.Name, hash, token.FormatScopes(t.Scopes), t.CreatedAt, t.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (s *SQLiteStorage) GetTokens() ([]token.Token, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT id, name, scopes, created_at, last_used_at, expires_at
+		FROM api_tokens
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []token.Token
+
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// GetTokenByHash returns ErrTokenNotFound if no token has the given hash.
+// Expiry is left to the caller so that it can tell the two cases apart.
+func (s *SQLiteStorage) GetTokenByHash(hash string) (token.Token, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return token.Token{}, err
+	}
+	defer db.Close()
+
+	row := db.QueryRow(`
+		SELECT id, name, scopes, created_at, last_used_at, expires_at
+		FROM api_tokens
+		WHERE token_hash = ?
+	`, hash)
+
+	t, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return token.Token{}, ErrTokenNotFound
+	}
+
+	return t, err
+}
+
+func (s *SQLiteStorage) TouchToken(id int64, usedAt time.Time) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, id)
+	return err
+}
+
+// Revoked tokens are deleted outright; there's nothing worth keeping once a
+// token can no longer be used.
+func (s *SQLiteStorage) RevokeToken(id int64) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM api_tokens WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// Satisfied by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (token.Token, error) {
+	var t token.Token
+	var scopes string
+	var createdAt string
+	var lastUsedAt, expiresAt sql.NullString
+
+	err := row.Scan(&t.ID, &t.Name, &scopes, &createdAt, &lastUsedAt, &expiresAt)
+	if err != nil {
+		return token.Token{}, err
+	}
+
+	t.Scopes, err = token.ParseScopes(scopes)
+	if err != nil {
+		return token.Token{}, err
+	}
+
+	t.CreatedAt, err = parseTimestamp(createdAt)
+	if err != nil {
+		return token.Token{}, err
+	}
+
+	t.LastUsedAt, err = parseNullTimestamp(lastUsedAt)
+	if err != nil {
+		return token.Token{}, err
+	}
+
+	t.ExpiresAt, err = parseNullTimestamp(expiresAt)
+	if err != nil {
+		return token.Token{}, err
+	}
+
+	return t, nil
+}
+
+func parseNullTimestamp(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	parsed, err := parseTimestamp(value.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
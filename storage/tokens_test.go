@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/token"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+	testDatabasePath := "tokens_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	expiresAt := time.Now().Add(time.Hour)
+	original := token.Token{
+		Name:      "shortcut",
+		Scopes:    []token.Scope{token.ScopeRead, token.ScopeSearch},
+		CreatedAt: time.Now(),
+		ExpiresAt: &expiresAt,
+	}
+
+	_, hash, err := token.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := store.SaveToken(original, hash)
+	if err != nil {
+		t.Fatalf("error saving token: %v\n", err)
+	}
+
+	found, err := store.GetTokenByHash(hash)
+	if err != nil {
+		t.Fatalf("error getting token by hash: %v\n", err)
+	}
+
+	if found.ID != id || found.Name != original.Name {
+		t.Errorf("expected token %d %q, got %d %q", id, original.Name, found.ID, found.Name)
+	}
+
+	if !found.HasScope(token.ScopeSearch) || found.HasScope(token.ScopeWrite) {
+		t.Errorf("unexpected scopes %v", found.Scopes)
+	}
+
+	if found.ExpiresAt == nil || !found.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expected expiry %v, got %v", expiresAt, found.ExpiresAt)
+	}
+
+	if found.LastUsedAt != nil {
+		t.Errorf("new token should not have a last used time")
+	}
+
+	err = store.TouchToken(id, time.Now())
+	if err != nil {
+		t.Fatalf("error touching token: %v\n", err)
+	}
+
+	tokens, err := store.GetTokens()
+	if err != nil {
+		t.Fatalf("error getting tokens: %v\n", err)
+	}
+
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Fatalf("expected one used token, got %v", tokens)
+	}
+
+	err = store.RevokeToken(id)
+	if err != nil {
+		t.Fatalf("error revoking token: %v\n", err)
+	}
+
+	_, err = store.GetTokenByHash(hash)
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound after revoking, got %v", err)
+	}
+
+	err = store.RevokeToken(id)
+	if !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound when revoking twice, got %v", err)
+	}
+}
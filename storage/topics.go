@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"spire/cluster"
+	"spire/entry"
+	"strconv"
+	"strings"
+)
+
+var ErrTopicNotFound = errors.New("topic not found")
+
+// How many entries there were at the last full clustering run.
+const topicsClusteredCountSetting = "topics_clustered_count"
+
+// ReplaceTopics swaps out all topics and assignments after a full clustering
+// run. Each assignment's Topic is an index into topics.
+func (s *SQLiteStorage) ReplaceTopics(topics []cluster.Topic, assignments []cluster.Assignment) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM entry_topics")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM topics")
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]int, len(topics))
+	for _, assignment := range assignments {
+		sizes[assignment.Topic]++
+	}
+
+	ids := make([]int64, len(topics))
+	for i, topic := range topics {
+		centroid, err := json.Marshal(topic.Centroid)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			"INSERT INTO topics (keywords, centroid, size) VALUES (?, ?, ?)",
+			strings.Join(topic.Keywords, ","), string(centroid), sizes[i],
+		)
+		if err != nil {
+			return err
+		}
+
+		ids[i], err = result.LastInsertId()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, assignment := range assignments {
+		_, err = tx.Exec(
+			"INSERT INTO entry_topics (entry_id, topic_id, similarity) VALUES (?, ?, ?)",
+			assignment.EntryID, ids[assignment.Topic], assignment.Similarity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = setSetting(tx, topicsClusteredCountSetting, strconv.Itoa(len(assignments)))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddTopicAssignments places entries in existing topics without reclustering.
+// Each assignment's Topic is a topic ID.
+func (s *SQLiteStorage) AddTopicAssignments(assignments []cluster.Assignment) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, assignment := range assignments {
+		_, err = tx.Exec(`
+			INSERT INTO entry_topics (entry_id, topic_id, similarity) VALUES (?, ?, ?)
+			ON CONFLICT (entry_id) DO UPDATE SET topic_id = excluded.topic_id, similarity = excluded.similarity
+		`, assignment.EntryID, assignment.Topic, assignment.Similarity)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE topics SET size = (SELECT COUNT(*) FROM entry_topics WHERE topic_id = topics.id)")
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTopicsClusteredCount returns how many entries the last full clustering
+// run covered.
+func (s *SQLiteStorage) GetTopicsClusteredCount() (int, error) {
+	value, ok, err := s.GetSetting(topicsClusteredCountSetting)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return strconv.Atoi(value)
+}
+
+// GetTopics returns all topics, largest first.
+func (s *SQLiteStorage) GetTopics() ([]cluster.Topic, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, keywords, centroid, size FROM topics ORDER BY size DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []cluster.Topic
+
+	for rows.Next() {
+		topic, err := scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		topics = append(topics, topic)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+func (s *SQLiteStorage) GetTopic(id int64) (cluster.Topic, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return cluster.Topic{}, err
+	}
+	defer db.Close()
+
+	row := db.QueryRow("SELECT id, keywords, centroid, size FROM topics WHERE id = ?", id)
+
+	topic, err := scanTopic(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cluster.Topic{}, ErrTopicNotFound
+	}
+
+	return topic, err
+}
+
+func scanTopic(row scanner) (cluster.Topic, error) {
+	var topic cluster.Topic
+	var keywords string
+	var centroid string
+
+	err := row.Scan(&topic.ID, &keywords, &centroid, &topic.Size)
+	if err != nil {
+		return cluster.Topic{}, err
+	}
+
+	if keywords != "" {
+		topic.Keywords = strings.Split(keywords, ",")
+	}
+
+	topic.Centroid, err = entry.DeserializeEmbeddings(centroid)
+	if err != nil {
+		return cluster.Topic{}, err
+	}
+
+	return topic, nil
+}
+
+// GetTopicEntries returns a topic's entries, the most representative first.
+// A limit of zero returns all of them.
+func (s *SQLiteStorage) GetTopicEntries(topicID int64, limit int) ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if limit <= 0 {
+		limit = -1
+	}
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		JOIN entry_topics ON entry_topics.entry_id = entries.id
+		WHERE entry_topics.topic_id = ? AND entries.deleted_at IS NULL
+		ORDER BY entry_topics.similarity DESC
+		LIMIT ?
+	`, topicID, limit)
+}
+
+// GetEntriesWithoutTopic returns entries that haven't been placed in a topic
+// yet.
+func (s *SQLiteStorage) GetEntriesWithoutTopic() ([]entry.Entry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE id NOT IN (SELECT entry_id FROM entry_topics) AND deleted_at IS NULL
+		ORDER BY time
+	`)
+}
+
+// GetEntryTopicIDs maps each entry that's been placed in a topic to that
+// topic's ID.
+func (s *SQLiteStorage) GetEntryTopicIDs() (map[int64]int64, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT entry_id, topic_id FROM entry_topics")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	topicIDs := map[int64]int64{}
+
+	for rows.Next() {
+		var entryID, topicID int64
+		err := rows.Scan(&entryID, &topicID)
+		if err != nil {
+			return nil, err
+		}
+
+		topicIDs[entryID] = topicID
+	}
+
+	return topicIDs, rows.Err()
+}
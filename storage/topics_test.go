@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/cluster"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestTopics(t *testing.T) {
+	testDatabasePath := "topics_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+	var ids []int64
+	for _, content := range []string{"garden", "tomatoes", "deadline", "meeting"} {
+		id, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: content, Embedding: embedding})
+		if err != nil {
+			t.Fatalf("error saving entry: %v\n", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	err = store.ReplaceTopics(
+		[]cluster.Topic{
+			{Keywords: []string{"garden", "tomatoes"}, Centroid: entry.Vector{1, 0}},
+			{Keywords: []string{"work"}, Centroid: entry.Vector{0, 1}},
+		},
+		[]cluster.Assignment{
+			{EntryID: ids[0], Topic: 0, Similarity: 0.9},
+			{EntryID: ids[1], Topic: 0, Similarity: 0.8},
+			{EntryID: ids[2], Topic: 1, Similarity: 0.7},
+		},
+	)
+	if err != nil {
+		t.Fatalf("error replacing topics: %v\n", err)
+	}
+
+	topics, err := store.GetTopics()
+	if err != nil {
+		t.Fatalf("error getting topics: %v\n", err)
+	}
+
+	if len(topics) != 2 || topics[0].Size != 2 || topics[0].Label() != "garden · tomatoes" {
+		t.Fatalf("expected the garden topic first with 2 entries, got %v", topics)
+	}
+
+	count, err := store.GetTopicsClusteredCount()
+	if err != nil || count != 3 {
+		t.Errorf("expected a clustered count of 3, got %d (%v)", count, err)
+	}
+
+	pending, err := store.GetEntriesWithoutTopic()
+	if err != nil {
+		t.Fatalf("error getting entries without a topic: %v\n", err)
+	}
+
+	if len(pending) != 1 || pending[0].ID != ids[3] {
+		t.Fatalf("expected only the last entry to be without a topic, got %v", pending)
+	}
+
+	err = store.AddTopicAssignments([]cluster.Assignment{{EntryID: ids[3], Topic: topics[1].ID, Similarity: 0.95}})
+	if err != nil {
+		t.Fatalf("error adding assignments: %v\n", err)
+	}
+
+	work, err := store.GetTopic(topics[1].ID)
+	if err != nil {
+		t.Fatalf("error getting topic: %v\n", err)
+	}
+
+	if work.Size != 2 {
+		t.Errorf("expected the work topic to grow to 2, got %d", work.Size)
+	}
+
+	entries, err := store.GetTopicEntries(work.ID, 1)
+	if err != nil {
+		t.Fatalf("error getting topic entries: %v\n", err)
+	}
+
+	if len(entries) != 1 || entries[0].Content != "meeting" {
+		t.Errorf("expected the most similar entry, meeting, got %v", contents(entries))
+	}
+
+	_, err = store.GetTopic(-1)
+	if !errors.Is(err, ErrTopicNotFound) {
+		t.Errorf("expected ErrTopicNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"spire/entry"
+	"time"
+)
+
+// TrashedEntry is an entry in the trash, waiting to be restored or purged.
+type TrashedEntry struct {
+	entry.Entry
+	DeletedAt time.Time
+}
+
+// DeleteEntry moves an entry to the trash. Its topic and place on the map are
+// cleared, and links to it are left dangling, as if it didn't exist. Its tags
+// and its own links are kept for when it's restored.
+func (s *SQLiteStorage) DeleteEntry(id int64) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE entries SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrEntryNotFound
+	}
+
+	err = forgetEntry(tx, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// forgetEntry removes what other entries and the background jobs know about
+// an entry that's leaving the journal.
+func forgetEntry(tx *sql.Tx, id int64) error {
+	_, err := tx.Exec("UPDATE entry_links SET target_id = NULL WHERE target_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM entry_points WHERE entry_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM entry_topics WHERE entry_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE topics SET size = (SELECT COUNT(*) FROM entry_topics WHERE topic_id = topics.id)")
+	return err
+}
+
+// RestoreEntry takes an entry out of the trash. Links are resolved again in
+// both directions, since entries may have come and gone in the meantime. The
+// background jobs need to place it in a topic and on the map again.
+func (s *SQLiteStorage) RestoreEntry(id int64) error {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var content string
+	err = tx.QueryRow("SELECT content FROM entries WHERE id = ? AND deleted_at IS NOT NULL", id).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEntryNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	content, err = s.openContent(entryContent, id, content)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE entries SET deleted_at = NULL WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	err = s.replaceLinks(tx, id, content)
+	if err != nil {
+		return err
+	}
+
+	err = resolveDanglingLinks(tx, id, content)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTrashedEntries returns the entries in the trash, the most recently
+// deleted first.
+func (s *SQLiteStorage) GetTrashedEntries() ([]TrashedEntry, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	entries, err := s.queryEntries(db, `
+		SELECT `+entryColumns+`
+		FROM entries
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT id, deleted_at FROM entries WHERE deleted_at IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletedAt := map[int64]time.Time{}
+
+	for rows.Next() {
+		var id int64
+		var timeString string
+		err := rows.Scan(&id, &timeString)
+		if err != nil {
+			return nil, err
+		}
+
+		deletedAt[id], err = parseTimestamp(timeString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	trashed := make([]TrashedEntry, len(entries))
+	for i, e := range entries {
+		trashed[i] = TrashedEntry{Entry: e, DeletedAt: deletedAt[e.ID]}
+	}
+
+	return trashed, nil
+}
+
+// PurgeEntry permanently deletes an entry that's in the trash.
+func (s *SQLiteStorage) PurgeEntry(id int64) error {
+	purged, err := s.purgeEntries("id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if purged == 0 {
+		return ErrEntryNotFound
+	}
+
+	return nil
+}
+
+// PurgeEntriesDeletedBefore permanently deletes the entries that went into
+// the trash before the given time, and returns how many there were.
+func (s *SQLiteStorage) PurgeEntriesDeletedBefore(before time.Time) (int, error) {
+	return s.purgeEntries("unixepoch(deleted_at) < ?", before.Unix())
+}
+
+// purgeEntries permanently deletes the trashed entries matching condition.
+func (s *SQLiteStorage) purgeEntries(condition string, args ...any) (int, error) {
+	db, err := s.getDatabaseConnection()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id FROM entries WHERE deleted_at IS NOT NULL AND "+condition, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		// Deleting already cleared everything else that pointed at it.
+		for _, query := range []string{
+			"DELETE FROM entry_tags WHERE entry_id = ?",
+			"DELETE FROM entry_revisions WHERE entry_id = ?",
+			"DELETE FROM attachments WHERE entry_id = ?",
+			"DELETE FROM share_views WHERE share_id IN (SELECT id FROM entry_shares WHERE entry_id = ?)",
+			"DELETE FROM entry_shares WHERE entry_id = ?",
+			"DELETE FROM entry_links WHERE source_id = ?",
+			"DELETE FROM entries WHERE id = ?",
+		} {
+			_, err = tx.Exec(query, id)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(ids), tx.Commit()
+}
+
+// vectorCandidates is how many neighbors to ask the vector index for to end
+// up with n that aren't in the trash. The index doesn't know about the trash,
+// so at worst every trashed entry is nearer than the ones wanted.
+func vectorCandidates(db *sql.DB, n int) (int, error) {
+	var trashed int
+	err := db.QueryRow("SELECT COUNT(*) FROM entries WHERE deleted_at IS NOT NULL").Scan(&trashed)
+	return n + trashed, err
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"spire/cluster"
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	testDatabasePath := "trash_test.db"
+
+	store, err := NewSQLiteStorage(testDatabasePath)
+	if err != nil {
+		t.Fatalf("error creating storage: %v\n", err)
+	}
+
+	defer os.Remove(testDatabasePath)
+
+	embedding := generateRandomEmbeddings()
+
+	targetID, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "# Garden\n\nPlanted #tomatoes.", Embedding: embedding})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	sourceID, err := store.SaveEntry(entry.Entry{Time: time.Now(), Content: "Back to the [[Garden]].", Embedding: invert(embedding)})
+	if err != nil {
+		t.Fatalf("error saving entry: %v\n", err)
+	}
+
+	err = store.ReplaceTopics(
+		[]cluster.Topic{{Keywords: []string{"garden"}, Centroid: entry.Vector{1, 0}}},
+		[]cluster.Assignment{{EntryID: targetID, Topic: 0, Similarity: 1}, {EntryID: sourceID, Topic: 0, Similarity: 1}},
+	)
+	if err != nil {
+		t.Fatalf("error replacing topics: %v\n", err)
+	}
+
+	err = store.DeleteEntry(targetID)
+	if err != nil {
+		t.Fatalf("error deleting entry: %v\n", err)
+	}
+
+	_, err = store.GetEntry(targetID)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected a deleted entry to be hidden, got %v", err)
+	}
+
+	searched, err := store.SearchEntriesEmbedding(embedding)
+	if err != nil {
+		t.Fatalf("error searching: %v\n", err)
+	}
+
+	if len(searched) != 1 || searched[0].ID != sourceID {
+		t.Errorf("expected vector search to skip the deleted entry, got %v", contents(searched))
+	}
+
+	tags, err := store.GetTagCounts(true)
+	if err != nil || len(tags) != 0 {
+		t.Errorf("expected the deleted entry's tags to be left out, got %v (%v)", tags, err)
+	}
+
+	source, err := store.GetEntry(sourceID)
+	if err != nil || source.Links[0].TargetID != 0 {
+		t.Errorf("expected links to the deleted entry to dangle, got %v (%v)", source.Links, err)
+	}
+
+	topics, err := store.GetTopics()
+	if err != nil || topics[0].Size != 1 {
+		t.Errorf("expected the topic to shrink to 1, got %v (%v)", topics, err)
+	}
+
+	err = store.DeleteEntry(targetID)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected deleting twice to fail with ErrEntryNotFound, got %v", err)
+	}
+
+	trashed, err := store.GetTrashedEntries()
+	if err != nil {
+		t.Fatalf("error getting trash: %v\n", err)
+	}
+
+	if len(trashed) != 1 || trashed[0].ID != targetID || trashed[0].DeletedAt.IsZero() {
+		t.Fatalf("expected the deleted entry in the trash, got %v", trashed)
+	}
+
+	err = store.RestoreEntry(targetID)
+	if err != nil {
+		t.Fatalf("error restoring entry: %v\n", err)
+	}
+
+	source, err = store.GetEntry(sourceID)
+	if err != nil || source.Links[0].TargetID != targetID {
+		t.Errorf("expected links to resolve again after restoring, got %v (%v)", source.Links, err)
+	}
+
+	without, err := store.GetEntriesWithoutTopic()
+	if err != nil || len(without) != 1 || without[0].ID != targetID {
+		t.Errorf("expected the restored entry to need a topic again, got %v (%v)", without, err)
+	}
+
+	err = store.DeleteEntry(sourceID)
+	if err != nil {
+		t.Fatalf("error deleting entry: %v\n", err)
+	}
+
+	purged, err := store.PurgeEntriesDeletedBefore(time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Errorf("expected nothing old enough to purge, got %d (%v)", purged, err)
+	}
+
+	purged, err = store.PurgeEntriesDeletedBefore(time.Now().Add(time.Hour))
+	if err != nil || purged != 1 {
+		t.Errorf("expected the deleted entry to be purged, got %d (%v)", purged, err)
+	}
+
+	err = store.RestoreEntry(sourceID)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected a purged entry to be gone, got %v", err)
+	}
+
+	err = store.PurgeEntry(targetID)
+	if !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected purging an entry outside the trash to fail, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Package thumbnail makes small previews of uploaded images.
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// MaxPixels guards against images that are small files but decode to huge
+// bitmaps.
+const MaxPixels = 50_000_000
+
+// Make decodes a JPEG, PNG or GIF and returns a JPEG no wider or taller than
+// size pixels. Images already that small are re-encoded at their own size.
+func Make(r io.Reader, size int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return nil, image.ErrFormat
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = jpeg.Encode(&b, Scale(source, size), &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Scale shrinks img to fit in a size by size square, keeping its aspect
+// ratio, by averaging the source pixels behind each target pixel.
+// Transparent areas come out white, since JPEG has no transparency.
+func Scale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	targetWidth, targetHeight := width, height
+	if width > size || height > size {
+		if width >= height {
+			targetWidth, targetHeight = size, max(1, height*size/width)
+		} else {
+			targetWidth, targetHeight = max(1, width*size/height), size
+		}
+	}
+
+	// Flattening onto white first keeps the averaging simple.
+	flat := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+
+	for y := 0; y < targetHeight; y++ {
+		top, bottom := y*height/targetHeight, max((y+1)*height/targetHeight, y*height/targetHeight+1)
+
+		for x := 0; x < targetWidth; x++ {
+			left, right := x*width/targetWidth, max((x+1)*width/targetWidth, x*width/targetWidth+1)
+
+			var r, g, b, count uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					c := flat.RGBAAt(sx, sy)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					count++
+				}
+			}
+
+			scaled.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / count),
+				G: uint8(g / count),
+				B: uint8(b / count),
+				A: 255,
+			})
+		}
+	}
+
+	return scaled
+}
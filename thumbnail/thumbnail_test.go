@@ -0,0 +1,72 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestScale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			// Left half black, right half opaque red.
+			if x >= 200 {
+				img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{A: 255})
+			}
+		}
+	}
+
+	scaled := Scale(img, 100)
+
+	if scaled.Bounds().Dx() != 100 || scaled.Bounds().Dy() != 50 {
+		t.Fatalf("expected 100x50 keeping the aspect ratio, got %v", scaled.Bounds())
+	}
+
+	r, g, b, _ := scaled.At(10, 10).RGBA()
+	if r != 0 || g != 0 || b != 0 {
+		t.Errorf("expected the left to stay black, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+
+	r, _, _, _ = scaled.At(90, 10).RGBA()
+	if r>>8 != 255 {
+		t.Errorf("expected the right to stay red, got %d", r>>8)
+	}
+
+	small := Scale(image.NewRGBA(image.Rect(0, 0, 20, 10)), 100)
+	if small.Bounds().Dx() != 20 || small.Bounds().Dy() != 10 {
+		t.Errorf("expected a small image to keep its size, got %v", small.Bounds())
+	}
+}
+
+func TestMake(t *testing.T) {
+	var b bytes.Buffer
+	err := png.Encode(&b, image.NewNRGBA(image.Rect(0, 0, 300, 600)))
+	if err != nil {
+		t.Fatalf("error encoding png: %v\n", err)
+	}
+
+	data, err := Make(&b, 120)
+	if err != nil {
+		t.Fatalf("error making thumbnail: %v\n", err)
+	}
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a jpeg: %v\n", err)
+	}
+
+	if thumbnail.Bounds().Dx() != 60 || thumbnail.Bounds().Dy() != 120 {
+		t.Errorf("expected 60x120, got %v", thumbnail.Bounds())
+	}
+
+	_, err = Make(bytes.NewReader([]byte("not an image")), 120)
+	if err == nil {
+		t.Errorf("expected an error for something that isn't an image")
+	}
+}
@@ -0,0 +1,91 @@
+package token
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	plaintext, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(plaintext, Prefix) {
+		t.Errorf("expected token to start with %s, got %s", Prefix, plaintext)
+	}
+
+	if hash != Hash(plaintext) {
+		t.Errorf("hash does not match plaintext")
+	}
+
+	other, _, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plaintext == other {
+		t.Errorf("expected two generated tokens to differ")
+	}
+}
+
+func TestGenerateWithPrefix(t *testing.T) {
+	plaintext, hash, err := GenerateWithPrefix(SharePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(plaintext, SharePrefix) || strings.HasPrefix(plaintext, Prefix) {
+		t.Errorf("expected token to start with %s only, got %s", SharePrefix, plaintext)
+	}
+
+	if hash != Hash(plaintext) {
+		t.Errorf("hash does not match plaintext")
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	actual, err := ParseScopes("read, search,read")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Scope{ScopeRead, ScopeSearch}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if FormatScopes(actual) != "read,search" {
+		t.Errorf("expected read,search, got %s", FormatScopes(actual))
+	}
+
+	_, err = ParseScopes("read,admin")
+	if err == nil {
+		t.Errorf("expected an error for an unknown scope")
+	}
+
+	_, err = ParseScopes("")
+	if err == nil {
+		t.Errorf("expected an error for empty scopes")
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	if (Token{}).Expired(now) {
+		t.Errorf("token without expiry should never expire")
+	}
+
+	if !(Token{ExpiresAt: &past}).Expired(now) {
+		t.Errorf("token with past expiry should be expired")
+	}
+
+	if (Token{ExpiresAt: &future}).Expired(now) {
+		t.Errorf("token with future expiry should not be expired")
+	}
+}
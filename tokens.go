@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spire/storage"
+	"spire/token"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type settingsPage struct {
+	CSRFToken string
+	Tokens    []token.Token
+	AllScopes []token.Scope
+}
+
+// Shown once, right after a token is created.
+type newTokenFragment struct {
+	Token     token.Token
+	Plaintext string
+}
+
+// createToken stores a new token and returns its plaintext. A lifetime of
+// zero means the token never expires.
+func createToken(store *storage.SQLiteStorage, name string, scopes []token.Scope, lifetime time.Duration) (token.Token, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return token.Token{}, "", errors.New("token name is required")
+	}
+
+	plaintext, hash, err := token.Generate()
+	if err != nil {
+		return token.Token{}, "", err
+	}
+
+	t := token.Token{
+		Name:      strings.TrimSpace(name),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+
+	if lifetime > 0 {
+		expiresAt := t.CreatedAt.Add(lifetime)
+		t.ExpiresAt = &expiresAt
+	}
+
+	t.ID, err = store.SaveToken(t, hash)
+	if err != nil {
+		return token.Token{}, "", err
+	}
+
+	return t, plaintext, nil
+}
+
+func (server *Server) settingsHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := server.Storage.GetTokens()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "settings.html", settingsPage{
+		CSRFToken: csrfToken,
+		Tokens:    tokens,
+		AllScopes: token.AllScopes,
+	})
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) newTokenHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	scopes, err := token.ParseScopes(strings.Join(r.PostForm["scopes"], ","))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	days, err := strconv.Atoi(r.PostForm.Get("expires_in_days"))
+	if err != nil || days < 0 {
+		http.Error(w, "invalid expiry", http.StatusBadRequest)
+		return
+	}
+
+	t, plaintext, err := createToken(&server.Storage, r.PostForm.Get("name"), scopes, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "new-token", newTokenFragment{Token: t, Plaintext: plaintext})
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) revokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.RevokeToken(id)
+	if errors.Is(err, storage.ErrTokenNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// htmx removes the row on an empty 200.
+	w.WriteHeader(http.StatusOK)
+}
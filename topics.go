@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spire/cluster"
+	"spire/entry"
+	"spire/storage"
+	"strconv"
+)
+
+const (
+	topicKeywordCount = 6
+	// Once this share of entries has arrived since the last full run, the
+	// existing topics are stale enough to recluster from scratch.
+	reclusterRatio = 0.2
+	// Fixed so that rerunning over the same entries gives the same topics.
+	clusterSeed          = 42
+	clusterMaxIterations = 50
+	representativeCount  = 3
+)
+
+// refreshTopics places new entries in the nearest existing topic, or
+// reclusters everything when the topics have drifted too far from the
+// entries they were built from.
+func (server *Server) refreshTopics() error {
+	topics, err := server.Storage.GetTopics()
+	if err != nil {
+		return err
+	}
+
+	pending, err := server.Storage.GetEntriesWithoutTopic()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 && len(topics) > 0 {
+		return nil
+	}
+
+	clustered, err := server.Storage.GetTopicsClusteredCount()
+	if err != nil {
+		return err
+	}
+
+	if len(topics) == 0 || float64(len(pending)) > reclusterRatio*float64(clustered) {
+		return server.reclusterTopics()
+	}
+
+	centroids := make([]entry.Vector, len(topics))
+	for i, topic := range topics {
+		centroids[i] = topic.Centroid
+	}
+
+	assignments := make([]cluster.Assignment, len(pending))
+	for i, e := range pending {
+		nearest, similarity := cluster.Nearest(centroids, e.Embedding)
+		assignments[i] = cluster.Assignment{
+			EntryID:    e.ID,
+			Topic:      topics[nearest].ID,
+			Similarity: similarity,
+		}
+	}
+
+	return server.Storage.AddTopicAssignments(assignments)
+}
+
+// reclusterTopics rebuilds every topic from the stored embeddings.
+func (server *Server) reclusterTopics() error {
+	entries, err := server.Storage.GetEntries()
+	if err != nil {
+		return err
+	}
+
+	k := cluster.ChooseK(len(entries))
+	if k == 0 {
+		return server.Storage.ReplaceTopics(nil, nil)
+	}
+
+	vectors := make([]entry.Vector, len(entries))
+	contents := make([]string, len(entries))
+	for i, e := range entries {
+		vectors[i] = e.Embedding
+		// Topic labels are shown to everyone, so private entries only help
+		// decide which topic they're in and don't lend it any words.
+		if !e.Private {
+			contents[i] = e.Content
+		}
+	}
+
+	result := cluster.KMeans(vectors, k, clusterSeed, clusterMaxIterations)
+	keywords := cluster.Keywords(contents, result.Assignments, len(result.Centroids), topicKeywordCount)
+
+	topics := make([]cluster.Topic, len(result.Centroids))
+	for i, centroid := range result.Centroids {
+		topics[i] = cluster.Topic{Keywords: keywords[i], Centroid: centroid}
+	}
+
+	assignments := make([]cluster.Assignment, len(entries))
+	for i, e := range entries {
+		topic := result.Assignments[i]
+		assignments[i] = cluster.Assignment{
+			EntryID:    e.ID,
+			Topic:      int64(topic),
+			Similarity: result.Centroids[topic].CosineSimilarity(e.Embedding),
+		}
+	}
+
+	return server.Storage.ReplaceTopics(topics, assignments)
+}
+
+type topicSummary struct {
+	Topic           cluster.Topic
+	Representatives []entry.Entry
+}
+
+type topicsPage struct {
+	CSRFToken string
+	Topics    []topicSummary
+}
+
+func (server *Server) topicsHandler(w http.ResponseWriter, r *http.Request) {
+	topics, err := server.Storage.GetTopics()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := topicsPage{}
+	for _, topic := range topics {
+		representatives, err := server.Storage.GetTopicEntries(topic.ID, representativeCount)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page.Topics = append(page.Topics, topicSummary{Topic: topic, Representatives: representatives})
+	}
+
+	page.CSRFToken, err = server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "topics.html", page)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) topicEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid topic id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = server.Storage.GetTopic(id)
+	if errors.Is(err, storage.ErrTopicNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := server.Storage.GetTopicEntries(id, 0)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "entries.html", entries)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// reclusterHandler rebuilds topics right away, for when the incremental
+// assignments have drifted and waiting isn't an option.
+func (server *Server) reclusterHandler(w http.ResponseWriter, r *http.Request) {
+	err := server.reclusterTopics()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"spire/storage"
+	"time"
+)
+
+// How long entries stay in the trash before they're deleted for good, unless
+// SPIRE_TRASH_DAYS says otherwise.
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
+)
+
+// deleteEntryHandler moves an entry to the trash and swaps it for a notice
+// with a button to undo.
+func (server *Server) deleteEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := server.Storage.GetEntry(id)
+	if err == nil {
+		err = server.Storage.DeleteEntry(id)
+	}
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "deleted-toast", deleted)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderTagCloudOOB(w, r)
+}
+
+// restoreEntryHandler takes an entry out of the trash. From the undo notice
+// it swaps the entry back in; from the trash page the page just reloads.
+func (server *Server) restoreEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.RestoreEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.TopicsJob.Trigger()
+	server.MapJob.Trigger()
+
+	if r.URL.Query().Get("from") == "trash" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+
+	restored, err := server.Storage.GetEntry(id)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "entry.html", restored)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	server.renderTagCloudOOB(w, r)
+}
+
+func (server *Server) trashHandler(w http.ResponseWriter, r *http.Request) {
+	trashed, err := server.Storage.GetTrashedEntries()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		CSRFToken string
+		Entries   []storage.TrashedEntry
+		// Zero if entries stay until they're purged by hand.
+		RetentionDays int
+	}{
+		CSRFToken:     csrfToken,
+		Entries:       trashed,
+		RetentionDays: int(server.TrashRetention / (24 * time.Hour)),
+	}
+
+	err = templates.ExecuteTemplate(w, "trash.html", data)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (server *Server) purgeEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := entryIDFromPath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.PurgeEntry(id)
+	if errors.Is(err, storage.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+}
+
+func (server *Server) emptyTrashHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := server.Storage.PurgeEntriesDeletedBefore(time.Now())
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+}
+
+// purgeTrash is the trash job's run function. It deletes entries that have
+// been in the trash longer than the retention period, and then any files
+// that no entry has anymore.
+func (server *Server) purgeTrash() error {
+	if server.TrashRetention > 0 {
+		purged, err := server.Storage.PurgeEntriesDeletedBefore(time.Now().Add(-server.TrashRetention))
+		if err != nil {
+			return err
+		}
+
+		if purged > 0 {
+			log.Printf("trash: purged %d entries\n", purged)
+		}
+	}
+
+	return server.collectBlobs()
+}
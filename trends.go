@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"spire/stats"
+	"spire/storage"
+	"spire/trends"
+	"strconv"
+	"strings"
+)
+
+// loadTrends scores each month against the user's themes followed by the
+// discovered topics.
+func (server *Server) loadTrends() ([]trends.Month, []trends.Series, error) {
+	entries, err := server.Storage.GetEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	themes, err := server.Storage.GetThemes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topics, err := server.Storage.GetTopics()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, topic := range topics {
+		themes = append(themes, trends.Theme{ID: topic.ID, Name: topic.Label(), Vector: topic.Centroid})
+	}
+
+	months := trends.MonthlyCentroids(entries, server.Location)
+
+	return months, trends.Compute(months, themes), nil
+}
+
+type trendsLegendItem struct {
+	Theme trends.Theme
+	Color string
+}
+
+type trendsPage struct {
+	CSRFToken string
+	Chart     template.HTML
+	Legend    []trendsLegendItem
+}
+
+func (server *Server) trendsHandler(w http.ResponseWriter, r *http.Request) {
+	months, series, err := server.loadTrends()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	labels := make([]string, len(months))
+	for i, month := range months {
+		labels[i] = month.Start.Format("Jan '06")
+	}
+
+	page := trendsPage{}
+
+	chartSeries := make([]stats.ChartSeries, len(series))
+	for i, s := range series {
+		chartSeries[i] = stats.ChartSeries{Name: s.Theme.Name, Values: s.Values}
+		page.Legend = append(page.Legend, trendsLegendItem{
+			Theme: s.Theme,
+			Color: stats.Palette[i%len(stats.Palette)],
+		})
+	}
+
+	if len(months) > 0 && len(series) > 0 {
+		// Aim for about eight labels on the month axis, as on the stats page.
+		page.Chart = stats.MultiLineChart("Theme prominence per month", labels, chartSeries, max(1, len(labels)/8))
+	}
+
+	page.CSRFToken, err = server.Sessions.CSRFToken(w, r)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = templates.ExecuteTemplate(w, "trends.html", page)
+
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) newThemeHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		http.Error(w, "a theme needs a name", http.StatusBadRequest)
+		return
+	}
+
+	vector, err := server.VoyageClient.GetEmbedding(name)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = server.Storage.SaveTheme(name, vector)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/trends", http.StatusSeeOther)
+}
+
+func (server *Server) deleteThemeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid theme id", http.StatusBadRequest)
+		return
+	}
+
+	err = server.Storage.DeleteTheme(id)
+	if errors.Is(err, storage.ErrThemeNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type apiTrendMonth struct {
+	Month   string `json:"month"`
+	Entries int    `json:"entries"`
+}
+
+type apiTrendTheme struct {
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	// One similarity per month, in the same order as months.
+	Values []float64 `json:"values"`
+}
+
+type apiTrends struct {
+	Months []apiTrendMonth `json:"months"`
+	Themes []apiTrendTheme `json:"themes"`
+}
+
+func (server *Server) apiTrendsHandler(w http.ResponseWriter, r *http.Request) {
+	months, series, err := server.loadTrends()
+	if err != nil {
+		log.Println(err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := apiTrends{
+		Months: make([]apiTrendMonth, len(months)),
+		Themes: make([]apiTrendTheme, len(series)),
+	}
+
+	for i, month := range months {
+		response.Months[i] = apiTrendMonth{Month: month.Start.Format("2006-01"), Entries: month.Entries}
+	}
+
+	for i, s := range series {
+		response.Themes[i] = apiTrendTheme{Name: s.Theme.Name, Custom: s.Theme.Custom, Values: s.Values}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,70 @@
+package trends
+
+import (
+	"sort"
+	"spire/entry"
+	"time"
+)
+
+// A Theme is something to follow over time: either a phrase the user typed
+// in, or a topic found by clustering.
+type Theme struct {
+	ID     int64
+	Name   string
+	Vector entry.Vector
+	// Added by the user rather than discovered from topics.
+	Custom bool
+}
+
+// A Month is the average of the embeddings written in it.
+type Month struct {
+	Start    time.Time
+	Entries  int
+	Centroid entry.Vector
+}
+
+// Series is how close each month came to one theme.
+type Series struct {
+	Theme Theme
+	// Values[i] is the cosine similarity of the i-th month to the theme.
+	Values []float64
+}
+
+// MonthlyCentroids groups entries by calendar month in loc, oldest first.
+// Months without entries are left out rather than given a made-up centroid.
+func MonthlyCentroids(entries []entry.Entry, loc *time.Location) []Month {
+	vectors := map[time.Time][]entry.Vector{}
+	for _, e := range entries {
+		local := e.Time.In(loc)
+		start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		vectors[start] = append(vectors[start], e.Embedding)
+	}
+
+	months := make([]Month, 0, len(vectors))
+	for start, monthVectors := range vectors {
+		months = append(months, Month{
+			Start:    start,
+			Entries:  len(monthVectors),
+			Centroid: entry.Mean(monthVectors),
+		})
+	}
+
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Start.Before(months[j].Start)
+	})
+
+	return months
+}
+
+// Compute scores every month against every theme.
+func Compute(months []Month, themes []Theme) []Series {
+	series := make([]Series, len(themes))
+	for i, theme := range themes {
+		series[i] = Series{Theme: theme, Values: make([]float64, len(months))}
+		for j, month := range months {
+			series[i].Values[j] = month.Centroid.CosineSimilarity(theme.Vector)
+		}
+	}
+
+	return series
+}
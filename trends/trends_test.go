@@ -0,0 +1,63 @@
+package trends
+
+import (
+	"spire/entry"
+	"testing"
+	"time"
+)
+
+func TestMonthlyCentroids(t *testing.T) {
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	entries := []entry.Entry{
+		{Time: at(time.March, 2), Embedding: entry.Vector{0, 1}},
+		{Time: at(time.January, 5), Embedding: entry.Vector{1, 0}},
+		{Time: at(time.January, 20), Embedding: entry.Vector{0, 1}},
+		// Still January in UTC, but already February in Tokyo.
+		{Time: time.Date(2024, time.January, 31, 20, 0, 0, 0, time.UTC), Embedding: entry.Vector{0, 1}},
+	}
+
+	months := MonthlyCentroids(entries, time.UTC)
+	if len(months) != 2 {
+		t.Fatalf("expected 2 months, got %v", months)
+	}
+
+	if months[0].Start.Month() != time.January || months[0].Entries != 3 {
+		t.Errorf("expected January first with 3 entries, got %v", months[0])
+	}
+
+	if months[0].Centroid[0] < 0.33 || months[0].Centroid[0] > 0.34 {
+		t.Errorf("expected January's centroid to average its entries, got %v", months[0].Centroid)
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("error loading location: %v\n", err)
+	}
+
+	months = MonthlyCentroids(entries, tokyo)
+	if len(months) != 3 || months[1].Start.Month() != time.February {
+		t.Errorf("expected the late January entry to count for February in Tokyo, got %v", months)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	months := []Month{
+		{Centroid: entry.Vector{1, 0}},
+		{Centroid: entry.Vector{1, 1}},
+		{Centroid: entry.Vector{0, 1}},
+	}
+
+	series := Compute(months, []Theme{{Name: "work", Vector: entry.Vector{0, 2}}})
+
+	if len(series) != 1 || len(series[0].Values) != 3 {
+		t.Fatalf("expected one series over 3 months, got %v", series)
+	}
+
+	values := series[0].Values
+	if values[0] != 0 || values[2] < 0.999 || values[1] <= values[0] || values[1] >= values[2] {
+		t.Errorf("expected the theme to rise over the months, got %v", values)
+	}
+}
@@ -38,9 +38,61 @@ func parseEmbeddingResponse(input []byte) (voyageEmbeddingResponse, error) {
 }
 
 func (vc VoyageClient) GetEmbedding(input string) (entry.Vector, error) {
+	result, err := vc.requestEmbeddings(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) != 1 {
+		return nil, errors.New(fmt.Sprintf(
+			"expected 1 result from API, but got %d",
+			len(result.Data),
+		))
+	}
+
+	return result.Data[0].Embedding, err
+}
+
+// The most inputs the API accepts in one request.
+const MaxBatchSize = 128
+
+// GetEmbeddings embeds several inputs in one request. The results are in the
+// same order as the inputs.
+func (vc VoyageClient) GetEmbeddings(inputs []string) ([]entry.Vector, error) {
+	if len(inputs) > MaxBatchSize {
+		return nil, fmt.Errorf("can embed at most %d inputs at once, got %d", MaxBatchSize, len(inputs))
+	}
+
+	result, err := vc.requestEmbeddings(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) != len(inputs) {
+		return nil, errors.New(fmt.Sprintf(
+			"expected %d results from API, but got %d",
+			len(inputs),
+			len(result.Data),
+		))
+	}
+
+	embeddings := make([]entry.Vector, len(inputs))
+	for _, data := range result.Data {
+		if data.Index < 0 || data.Index >= len(inputs) {
+			return nil, fmt.Errorf("API returned an out of range index %d", data.Index)
+		}
+
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// input is either a single string or a list of them.
+func (vc VoyageClient) requestEmbeddings(input any) (voyageEmbeddingResponse, error) {
 	requestBody := struct {
 		Model string `json:"model"`
-		Input string `json:"input"`
+		Input any    `json:"input"`
 	}{
 		Model: "voyage-3-lite",
 		Input: input,
@@ -48,7 +100,7 @@ func (vc VoyageClient) GetEmbedding(input string) (entry.Vector, error) {
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		return nil, err
+		return voyageEmbeddingResponse{}, err
 	}
 
 	request, err := http.NewRequest(
@@ -57,7 +109,7 @@ func (vc VoyageClient) GetEmbedding(input string) (entry.Vector, error) {
 		bytes.NewBuffer(jsonBody),
 	)
 	if err != nil {
-		return nil, err
+		return voyageEmbeddingResponse{}, err
 	}
 
 	request.Header.Set("Content-Type", "application/json")
@@ -66,31 +118,19 @@ func (vc VoyageClient) GetEmbedding(input string) (entry.Vector, error) {
 	client := &http.Client{}
 	resp, err := client.Do(request)
 	if err != nil {
-		return nil, err
+		return voyageEmbeddingResponse{}, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf(
+		return voyageEmbeddingResponse{}, errors.New(fmt.Sprintf(
 			"server returned status %d with data %s",
 			resp.StatusCode,
 			string(body),
 		))
 	}
 
-	result, err := parseEmbeddingResponse(body)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(result.Data) != 1 {
-		return nil, errors.New(fmt.Sprintf(
-			"expected 1 result from API, but got %d",
-			len(result.Data),
-		))
-	}
-
-	return result.Data[0].Embedding, err
+	return parseEmbeddingResponse(body)
 }